@@ -0,0 +1,28 @@
+package client
+
+import "net/http"
+
+// Credentials authenticate requests to a cluster. A bearer token takes
+// precedence over basic auth.
+type Credentials struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// WithCredentials sets the credentials sent with every request.
+func WithCredentials(creds Credentials) Option {
+	return func(c *Client) {
+		c.creds = creds
+	}
+}
+
+// authorize adds the client's credentials to req.
+func (c *Client) authorize(req *http.Request) {
+	switch {
+	case c.creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.creds.Token)
+	case c.creds.Username != "":
+		req.SetBasicAuth(c.creds.Username, c.creds.Password)
+	}
+}
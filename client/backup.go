@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Backup states reported by the server.
+const (
+	BackupPending   = "pending"
+	BackupRunning   = "running"
+	BackupCompleted = "completed"
+	BackupFailed    = "failed"
+)
+
+// ByteSize is a size in bytes. It decodes from a JSON number or from a
+// human readable string such as "1.5 GB".
+type ByteSize uint64
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var n uint64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = ByteSize(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid size %s", data)
+	}
+	if s == "" {
+		*b = 0
+		return nil
+	}
+	parsed, err := humanize.ParseBytes(s)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	*b = ByteSize(parsed)
+	return nil
+}
+
+// String formats the size for humans, e.g. "1.5 GB".
+func (b ByteSize) String() string {
+	return humanize.Bytes(uint64(b))
+}
+
+// Backup is a cluster backup as reported by the backup endpoints.
+type Backup struct {
+	Name        string   `json:"name"`
+	Size        ByteSize `json:"size"`
+	CreatedAt   string   `json:"created_at"`
+	State       string   `json:"state,omitempty"`
+	Progress    float64  `json:"progress,omitempty"`
+	CompletedAt string   `json:"completed_at,omitempty"`
+	Nodes       []string `json:"nodes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Done reports whether the backup is no longer in progress. Servers that
+// create backups synchronously do not report a state at all.
+func (b *Backup) Done() bool {
+	switch strings.ToLower(b.State) {
+	case BackupPending, BackupRunning, "in_progress":
+		return false
+	}
+	return true
+}
+
+// BackupCreate starts a backup with the given name. Long-running backups
+// are returned in the pending or running state; poll BackupDescribe until
+// Done reports true.
+func (c *Client) BackupCreate(ctx context.Context, name string) (*Backup, error) {
+	backup := Backup{Name: name}
+	if err := c.post(ctx, "backup/create/"+url.PathEscape(name), nil, &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// BackupList lists the backups known to the cluster.
+func (c *Client) BackupList(ctx context.Context) ([]Backup, error) {
+	var backups []Backup
+	if err := c.get(ctx, "backup/list", &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// BackupDescribe returns the details and progress of the named backup.
+func (c *Client) BackupDescribe(ctx context.Context, name string) (*Backup, error) {
+	var backup Backup
+	if err := c.get(ctx, "backup/describe/"+url.PathEscape(name), &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// BackupRestore restores the named backup.
+func (c *Client) BackupRestore(ctx context.Context, name string) error {
+	return c.post(ctx, "backup/restore/"+url.PathEscape(name), nil, nil)
+}
+
+// BackupDelete deletes the named backup.
+func (c *Client) BackupDelete(ctx context.Context, name string) error {
+	return c.post(ctx, "backup/delete/"+url.PathEscape(name), nil, nil)
+}
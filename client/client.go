@@ -0,0 +1,279 @@
+// Package client is a Go client for the gocluster HTTP API.
+//
+// A Client is built from a ClusterConfig and talks to the nodes of the
+// cluster in the order given by the cluster's node selection strategy.
+// All methods take a context.Context and return typed responses instead
+// of the raw APIResponse envelope.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ClusterConfig describes a gocluster cluster as found in .gocluster.yaml.
+type ClusterConfig struct {
+	Name  string            `mapstructure:"name" json:"name" yaml:"name"`
+	Nodes map[string]string `mapstructure:"nodes" json:"nodes" yaml:"nodes"`
+	Port  int               `mapstructure:"port" json:"port" yaml:"port"`
+
+	// Strategy selects which node requests go to, one of Strategies.
+	Strategy string `mapstructure:"strategy" json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	// Via is the node used by the pinned strategy.
+	Via string `mapstructure:"via" json:"via,omitempty" yaml:"via,omitempty"`
+}
+
+// APIResponse is the envelope every gocluster endpoint answers with.
+type APIResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+
+	// StatusCode is the HTTP status the envelope was received with.
+	StatusCode int `json:"-"`
+}
+
+// HTTPError is returned when a node answered with a non-2xx status and a
+// body that is not an APIResponse.
+type HTTPError struct {
+	Addr       string
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s answered %s", e.Addr, e.Status)
+}
+
+// APIError is returned when a node answered but reported success=false.
+type APIError struct {
+	Endpoint   string
+	Message    string
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: request failed", e.Endpoint)
+	}
+	return fmt.Sprintf("%s: %s", e.Endpoint, e.Message)
+}
+
+// Client talks to the nodes of a single cluster.
+type Client struct {
+	cluster  ClusterConfig
+	http     *http.Client
+	selector *selector
+	logf     func(format string, args ...interface{})
+	retries  int
+	creds    Credentials
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the per-request timeout. Zero disables the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.http.Timeout = d
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithLogf sets a function the client reports the nodes it talks to with,
+// e.g. for verbose output.
+func WithLogf(logf func(format string, args ...interface{})) Option {
+	return func(c *Client) {
+		c.logf = logf
+	}
+}
+
+// New returns a Client for the given cluster.
+func New(cluster ClusterConfig, opts ...Option) *Client {
+	c := &Client{
+		cluster:  cluster,
+		http:     &http.Client{},
+		selector: newSelector(),
+		logf:     func(string, ...interface{}) {},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Cluster returns the cluster configuration the client was built from.
+func (c *Client) Cluster() ClusterConfig {
+	return c.cluster
+}
+
+// get fetches endpoint from the first node that answers and decodes the
+// response data into out.
+func (c *Client) get(ctx context.Context, endpoint string, out interface{}) error {
+	targets, err := c.targets(ctx, false)
+	if err != nil {
+		return err
+	}
+	return c.getFrom(ctx, targets, endpoint, out)
+}
+
+func (c *Client) getFrom(ctx context.Context, targets []target, endpoint string, out interface{}) error {
+	var lastErr error
+	attempts := 0
+	for round := 0; round <= c.retries; round++ {
+		if round > 0 {
+			if !retriable(lastErr, true) || ctx.Err() != nil {
+				break
+			}
+			delay := backoff(round)
+			c.logf("retrying GET %s in %s (retry %d/%d)", endpoint, delay.Round(time.Millisecond), round, c.retries)
+			if !sleepCtx(ctx, delay) {
+				break
+			}
+		}
+
+		for _, t := range targets {
+			attempts++
+			c.logf("GET %s via %s (%s)", endpoint, t.id, t.addr)
+			resp, err := c.do(ctx, http.MethodGet, t.addr, endpoint, nil, nil)
+			if err == nil && !retriableStatus(resp.StatusCode, true) {
+				c.logAttempts(http.MethodGet, endpoint, attempts, nil)
+				return decodeData(endpoint, resp, out)
+			}
+			if err == nil {
+				err = statusError(endpoint, resp)
+			}
+			c.logf("%s: %v", t.id, err)
+			lastErr = err
+		}
+	}
+	c.logAttempts(http.MethodGet, endpoint, attempts, lastErr)
+	return fmt.Errorf("failed to fetch from any node: %w", lastErr)
+}
+
+// post sends payload as JSON to endpoint on a single node and decodes the
+// response data into out. Mutating requests are never replayed on another
+// node, and only retried if the node reports it did not process them.
+func (c *Client) post(ctx context.Context, endpoint string, payload, out interface{}) error {
+	return c.postIdempotent(ctx, endpoint, "", payload, out)
+}
+
+// postIdempotent is post for requests carrying an idempotency key, which
+// lets the server apply the request only once however often it is
+// received. Such requests are retried after any retriable failure, each
+// time on the next node.
+func (c *Client) postIdempotent(ctx context.Context, endpoint, key string, payload, out interface{}) error {
+	targets, err := c.targets(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("encoding payload: %w", err)
+		}
+	}
+	header := http.Header{}
+	if key != "" {
+		header.Set("Idempotency-Key", key)
+	}
+
+	for attempt := 1; ; attempt++ {
+		t := targets[0]
+		if key != "" {
+			t = targets[(attempt-1)%len(targets)]
+		}
+		c.logf("POST %s via %s (%s)", endpoint, t.id, t.addr)
+		resp, err := c.do(ctx, http.MethodPost, t.addr, endpoint, body, header)
+		if err == nil && !retriableStatus(resp.StatusCode, key != "") {
+			c.logAttempts(http.MethodPost, endpoint, attempt, nil)
+			return decodeData(endpoint, resp, out)
+		}
+		if err == nil {
+			err = statusError(endpoint, resp)
+		}
+		c.logf("%s: %v", t.id, err)
+
+		if attempt > c.retries || ctx.Err() != nil || !retriable(err, key != "") {
+			c.logAttempts(http.MethodPost, endpoint, attempt, err)
+			return err
+		}
+		delay := backoff(attempt)
+		c.logf("retrying POST %s in %s (retry %d/%d)", endpoint, delay.Round(time.Millisecond), attempt, c.retries)
+		if !sleepCtx(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// do performs a single request against addr and decodes the envelope.
+func (c *Client) do(ctx context.Context, method, addr, endpoint string, body []byte, header http.Header) (*APIResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("http://%s/api/%s", addr, endpoint), reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	c.authorize(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Redirects, e.g. from a follower to the leader, are followed by the
+	// http.Client; report where the request ended up.
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.Request.URL.Host != req.URL.Host {
+		c.logf("redirected to %s", resp.Request.URL.Host)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	var apiResp APIResponse
+	if err := json.Unmarshal(raw, &apiResp); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, &HTTPError{Addr: addr, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		return nil, fmt.Errorf("decoding response from %s: %w", addr, err)
+	}
+	apiResp.StatusCode = resp.StatusCode
+	return &apiResp, nil
+}
+
+func decodeData(endpoint string, resp *APIResponse, out interface{}) error {
+	if !resp.Success {
+		return &APIError{Endpoint: endpoint, Message: resp.Error, StatusCode: resp.StatusCode}
+	}
+	if out == nil || len(resp.Data) == 0 || string(resp.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("invalid response format for %s: %w", endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Health is the payload of api/health. Nodes answer either with a bare
+// status string or with an object carrying a status field.
+type Health struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (h *Health) UnmarshalJSON(b []byte) error {
+	var status string
+	if err := json.Unmarshal(b, &status); err == nil {
+		h.Status = status
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if s, ok := fields["status"].(string); ok {
+		h.Status = s
+	}
+	delete(fields, "status")
+	if len(fields) > 0 {
+		h.Details = fields
+	}
+	return nil
+}
+
+// Node is a cluster member as reported by api/nodes.
+type Node struct {
+	ID       string    `json:"id"`
+	Address  string    `json:"address"`
+	LastSeen time.Time `json:"last_seen"`
+	State    string    `json:"state"`
+}
+
+// Leader identifies the current cluster leader.
+type Leader struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// Metrics is the free-form metrics map reported by api/metrics.
+type Metrics map[string]interface{}
+
+// HealthProbe is the result of probing a single node's api/health.
+type HealthProbe struct {
+	Node       string        `json:"node"`
+	Address    string        `json:"address"`
+	Healthy    bool          `json:"healthy"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Latency    time.Duration `json:"-"`
+	Error      string        `json:"error,omitempty"`
+	Health     *Health       `json:"health,omitempty"`
+}
+
+// Health reports the health of the first node that answers.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var h Health
+	if err := c.get(ctx, "health", &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ProbeHealth queries api/health on the given node only. Failures are
+// reported in the probe rather than returned, so callers can tabulate
+// every node.
+func (c *Client) ProbeHealth(ctx context.Context, nodeID string) HealthProbe {
+	probe := HealthProbe{Node: nodeID}
+	addr, ok := c.cluster.Nodes[nodeID]
+	if !ok {
+		probe.Error = fmt.Sprintf("node %q not found in cluster %q", nodeID, c.cluster.Name)
+		return probe
+	}
+	probe.Address = addr
+
+	start := time.Now()
+	resp, err := c.do(ctx, http.MethodGet, addr, "health", nil, nil)
+	probe.Latency = time.Since(start)
+
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &httpErr):
+		probe.StatusCode = httpErr.StatusCode
+		probe.Error = err.Error()
+		return probe
+	case err != nil:
+		// The URL is implied by the node, keep only the cause.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		probe.Error = err.Error()
+		return probe
+	}
+
+	probe.StatusCode = resp.StatusCode
+	var h Health
+	if err := decodeData("health", resp, &h); err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.Health = &h
+	switch {
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		probe.Error = fmt.Sprintf("%s answered HTTP %d", addr, resp.StatusCode)
+	case strings.EqualFold(h.Status, "unhealthy"):
+		probe.Error = "node reports itself unhealthy"
+	default:
+		probe.Healthy = true
+	}
+	return probe
+}
+
+// Nodes lists the members of the cluster.
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	if err := c.get(ctx, "nodes", &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// Leader returns the current cluster leader.
+func (c *Client) Leader(ctx context.Context) (*Leader, error) {
+	var leader Leader
+	if err := c.get(ctx, "leader", &leader); err != nil {
+		return nil, err
+	}
+	return &leader, nil
+}
+
+// Metrics returns the cluster metrics.
+func (c *Client) Metrics(ctx context.Context) (Metrics, error) {
+	var metrics Metrics
+	if err := c.get(ctx, "metrics", &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// ConfigGet returns the remote cluster configuration.
+func (c *Client) ConfigGet(ctx context.Context) (map[string]interface{}, error) {
+	var cfg map[string]interface{}
+	if err := c.get(ctx, "config", &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ConfigSet updates a single key of the remote cluster configuration.
+func (c *Client) ConfigSet(ctx context.Context, key, value string) error {
+	payload := map[string]string{
+		"key":   key,
+		"value": value,
+	}
+	return c.post(ctx, "config/set", payload, nil)
+}
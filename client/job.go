@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operator job states reported by the server.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is an operator operation running across one or more nodes.
+type Job struct {
+	ID        string           `json:"id"`
+	Operator  string           `json:"operator"`
+	Operation string           `json:"operation"`
+	State     string           `json:"state"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	ExitCode  int              `json:"exit_code,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Payload   *OperatorPayload `json:"payload,omitempty"`
+	Nodes     []JobNode        `json:"nodes,omitempty"`
+}
+
+// JobNode is the progress and result of a job on a single node.
+type JobNode struct {
+	Node     string      `json:"node"`
+	State    string      `json:"state"`
+	Progress float64     `json:"progress,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// Done reports whether the job reached a final state.
+func (j *Job) Done() bool {
+	return isFinalJobState(j.State)
+}
+
+// Done reports whether the job finished on this node.
+func (n *JobNode) Done() bool {
+	return isFinalJobState(n.State)
+}
+
+func isFinalJobState(state string) bool {
+	switch strings.ToLower(state) {
+	case JobCompleted, JobFailed, JobCancelled, "succeeded", "success", "error", "canceled":
+		return true
+	}
+	return false
+}
+
+// Succeeded reports whether the job finished successfully on this node.
+func (n *JobNode) Succeeded() bool {
+	switch strings.ToLower(n.State) {
+	case JobCompleted, "succeeded", "success":
+		return true
+	}
+	return false
+}
+
+// FailedNodes returns the nodes the job did not complete on.
+func (j *Job) FailedNodes() []string {
+	var nodes []string
+	for _, node := range j.Nodes {
+		if node.Done() && !node.Succeeded() {
+			nodes = append(nodes, node.Node)
+		}
+	}
+	return nodes
+}
+
+// RetryPayload rebuilds the payload of a finished job so it can be
+// triggered again. With failedOnly, TargetNodes is narrowed to the nodes
+// the job failed on.
+func (j *Job) RetryPayload(failedOnly bool) (OperatorPayload, error) {
+	if j.Payload == nil {
+		return OperatorPayload{}, fmt.Errorf("job %s does not record the payload it was triggered with", j.ID)
+	}
+	if !j.Done() {
+		return OperatorPayload{}, fmt.Errorf("job %s is still %s; cancel it or wait for it to finish first", j.ID, j.State)
+	}
+
+	payload := *j.Payload
+	if payload.Operation == "" {
+		payload.Operation = j.Operation
+	}
+	if failedOnly {
+		failed := j.FailedNodes()
+		if len(failed) == 0 {
+			return OperatorPayload{}, fmt.Errorf("job %s has no failed nodes", j.ID)
+		}
+		payload.TargetNodes = failed
+	}
+	return payload, nil
+}
+
+// JobFilter narrows the jobs returned by Jobs.
+type JobFilter struct {
+	Operator string
+	State    string
+	Since    time.Time
+	Limit    int
+}
+
+// Job returns the job with the given ID.
+func (c *Client) Job(ctx context.Context, jobID string) (*Job, error) {
+	var job Job
+	if err := c.get(ctx, "operator/jobs/"+url.PathEscape(jobID), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Jobs lists recent operator jobs, newest first.
+func (c *Client) Jobs(ctx context.Context, filter JobFilter) ([]Job, error) {
+	query := url.Values{}
+	if filter.Operator != "" {
+		query.Set("operator", filter.Operator)
+	}
+	if filter.State != "" {
+		query.Set("state", filter.State)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.Format(time.RFC3339Nano))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	endpoint := "operator/jobs"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var jobs []Job
+	if err := c.get(ctx, endpoint, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CancelJob asks the server to stop a running job and returns its state.
+func (c *Client) CancelJob(ctx context.Context, jobID string) (*Job, error) {
+	job := Job{ID: jobID}
+	if err := c.post(ctx, "operator/jobs/"+url.PathEscape(jobID)+"/cancel", nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitJob polls the job every interval until it reaches a final state or
+// ctx is done. onUpdate, if set, is called with every polled state.
+func (c *Client) WaitJob(ctx context.Context, jobID string, interval time.Duration, onUpdate func(*Job)) (*Job, error) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	for {
+		job, err := c.Job(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if onUpdate != nil {
+			onUpdate(job)
+		}
+		if job.Done() {
+			return job, nil
+		}
+		if !sleepCtx(ctx, interval) {
+			return job, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned by StreamLogs when the server answered
+// a follow request with a regular, non-streaming response.
+var ErrStreamingUnsupported = errors.New("log streaming not supported by server")
+
+// LogsOptions controls which log lines are returned by Logs and StreamLogs.
+// The filters are passed to the server as query parameters; servers that do
+// not support them ignore them, so callers should filter again locally.
+type LogsOptions struct {
+	Lines int
+	// Offset and Since resume a stream after the last entry seen.
+	Offset int64
+	Since  time.Time
+	Until  time.Time
+	// Grep and Exclude are regular expressions lines must and must not match.
+	Grep    []string
+	Exclude []string
+	// Level is the minimum level of structured log lines, e.g. "warn".
+	Level string
+}
+
+// FollowOptions controls FollowLogs.
+type FollowOptions struct {
+	LogsOptions
+	// PollInterval is used when the server cannot stream. Defaults to 2s.
+	PollInterval time.Duration
+	// MaxBackoff caps the delay between reconnect attempts. Defaults to 30s.
+	MaxBackoff time.Duration
+	// OnReconnect, if set, is called before waiting to reconnect.
+	OnReconnect func(attempt int, delay time.Duration, err error)
+}
+
+// LogEntry is a single log line. Offset and Timestamp are only set when the
+// server reports them.
+type LogEntry struct {
+	Line      string    `json:"line"`
+	Offset    int64     `json:"offset,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// callbackError marks errors returned by the caller's callback so they are
+// passed through instead of triggering a reconnect.
+type callbackError struct{ err error }
+
+func (e callbackError) Error() string { return e.err.Error() }
+func (e callbackError) Unwrap() error { return e.err }
+
+func logsEndpoint(nodeID string, opts LogsOptions, follow bool) string {
+	query := url.Values{}
+	if opts.Lines > 0 {
+		query.Set("lines", strconv.Itoa(opts.Lines))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(opts.Offset, 10))
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.Format(time.RFC3339Nano))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("until", opts.Until.Format(time.RFC3339Nano))
+	}
+	for _, pattern := range opts.Grep {
+		query.Add("grep", pattern)
+	}
+	for _, pattern := range opts.Exclude {
+		query.Add("exclude", pattern)
+	}
+	if opts.Level != "" {
+		query.Set("level", opts.Level)
+	}
+	if follow {
+		query.Set("follow", "true")
+	}
+	endpoint := "logs/" + url.PathEscape(nodeID)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	return endpoint
+}
+
+// Logs returns the most recent log lines of the given node.
+func (c *Client) Logs(ctx context.Context, nodeID string, opts LogsOptions) ([]string, error) {
+	var raw []interface{}
+	if err := c.get(ctx, logsEndpoint(nodeID, opts, false), &raw); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(raw))
+	for _, line := range raw {
+		lines = append(lines, fmt.Sprint(line))
+	}
+	return lines, nil
+}
+
+// StreamLogs opens a single follow stream for the node's logs and calls fn
+// for every entry until the stream ends, ctx is cancelled or fn returns an
+// error. Server-sent events, NDJSON and plain chunked text are understood.
+func (c *Client) StreamLogs(ctx context.Context, nodeID string, opts LogsOptions, fn func(LogEntry) error) error {
+	endpoint := logsEndpoint(nodeID, opts, true)
+
+	targets, err := c.targets(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, t := range targets {
+		c.logf("GET %s via %s (%s)", endpoint, t.id, t.addr)
+		resp, err := c.openStream(ctx, t.addr, endpoint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		defer resp.Body.Close()
+		return readLogStream(resp, fn)
+	}
+	return fmt.Errorf("failed to stream from any node: %w", lastErr)
+}
+
+// openStream connects to addr. The client timeout only bounds the wait for
+// response headers; the body may stay open indefinitely.
+func (c *Client) openStream(ctx context.Context, addr, endpoint string) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, fmt.Sprintf("http://%s/api/%s", addr, endpoint), nil)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream, application/x-ndjson, text/plain")
+	c.authorize(req)
+
+	hc := *c.http
+	hc.Timeout = 0
+	var timer *time.Timer
+	if c.http.Timeout > 0 {
+		timer = time.AfterFunc(c.http.Timeout, cancel)
+	}
+	resp, err := hc.Do(req)
+	if timer != nil && !timer.Stop() {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("timed out waiting for %s", addr)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func readLogStream(resp *http.Response, fn func(LogEntry) error) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/json":
+		// The server ignored follow=true and sent the usual envelope.
+		io.Copy(io.Discard, resp.Body)
+		return ErrStreamingUnsupported
+	case "text/event-stream":
+		return readSSE(resp.Body, fn)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	scanner := newLineScanner(resp.Body)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		// Servers that do not set a Content-Type still answer with the
+		// envelope when they cannot stream.
+		if first && isEnvelope(line) {
+			io.Copy(io.Discard, resp.Body)
+			return ErrStreamingUnsupported
+		}
+		first = false
+		if err := fn(parseLogPayload(line)); err != nil {
+			return callbackError{err}
+		}
+	}
+	return scanner.Err()
+}
+
+func isEnvelope(line string) bool {
+	if !strings.HasPrefix(line, "{") {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return false
+	}
+	_, ok := fields["success"]
+	return ok
+}
+
+func readSSE(r io.Reader, fn func(LogEntry) error) error {
+	var data []string
+	var id string
+
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				entry := parseLogPayload(strings.Join(data, "\n"))
+				if offset, err := strconv.ParseInt(id, 10, 64); err == nil && entry.Offset == 0 {
+					entry.Offset = offset
+				}
+				if err := fn(entry); err != nil {
+					return callbackError{err}
+				}
+			}
+			data, id = nil, ""
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "id":
+			id = value
+		}
+	}
+	return scanner.Err()
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
+
+// parseLogPayload decodes one streamed entry, which may be a JSON object,
+// a JSON string or plain text.
+func parseLogPayload(payload string) LogEntry {
+	switch {
+	case strings.HasPrefix(payload, "{"):
+		var obj struct {
+			Line      string      `json:"line"`
+			Message   string      `json:"message"`
+			Msg       string      `json:"msg"`
+			Offset    int64       `json:"offset"`
+			Timestamp interface{} `json:"timestamp"`
+			Time      interface{} `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(payload), &obj); err != nil {
+			break
+		}
+		entry := LogEntry{Line: obj.Line, Offset: obj.Offset}
+		if entry.Line == "" {
+			entry.Line = obj.Message
+		}
+		if entry.Line == "" {
+			entry.Line = obj.Msg
+		}
+		if entry.Line == "" {
+			entry.Line = payload
+		}
+		for _, ts := range []interface{}{obj.Timestamp, obj.Time} {
+			if s, ok := ts.(string); ok {
+				if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					entry.Timestamp = t
+					break
+				}
+			}
+		}
+		return entry
+	case strings.HasPrefix(payload, `"`):
+		var line string
+		if err := json.Unmarshal([]byte(payload), &line); err == nil {
+			return LogEntry{Line: line}
+		}
+	}
+	return LogEntry{Line: payload}
+}
+
+// FollowLogs streams the node's logs until ctx is cancelled, reconnecting
+// with exponential backoff and resuming after the last entry seen whenever
+// the stream drops. Servers that cannot stream are polled instead, with
+// lines already printed skipped between polls.
+func (c *Client) FollowLogs(ctx context.Context, nodeID string, opts FollowOptions, fn func(LogEntry) error) error {
+	const initialBackoff = 500 * time.Millisecond
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	state := opts.LogsOptions
+	var last LogEntry
+	delay := initialBackoff
+	attempt := 0
+
+	for {
+		received := false
+		err := c.StreamLogs(ctx, nodeID, state, func(e LogEntry) error {
+			if isReplayed(e, last) {
+				return nil
+			}
+			received = true
+			last = e
+			if e.Offset > 0 {
+				state.Offset = e.Offset
+			}
+			if !e.Timestamp.IsZero() {
+				state.Since = e.Timestamp
+			}
+			return fn(e)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var cbErr callbackError
+		switch {
+		case errors.As(err, &cbErr):
+			return cbErr.err
+		case errors.Is(err, ErrStreamingUnsupported):
+			return c.pollLogs(ctx, nodeID, opts, fn)
+		}
+
+		// Only new lines are wanted once the initial backlog was sent.
+		state.Lines = 0
+		if received {
+			delay, attempt = initialBackoff, 0
+		}
+		attempt++
+		if err == nil {
+			err = io.EOF
+		}
+		if opts.OnReconnect != nil {
+			opts.OnReconnect(attempt, delay, err)
+		}
+		if !sleepCtx(ctx, delay) {
+			return ctx.Err()
+		}
+		delay *= 2
+		if delay > maxBackoff {
+			delay = maxBackoff
+		}
+	}
+}
+
+// isReplayed reports whether e was already delivered before a reconnect.
+func isReplayed(e, last LogEntry) bool {
+	switch {
+	case e.Offset > 0 && last.Offset > 0:
+		return e.Offset <= last.Offset
+	case !e.Timestamp.IsZero() && !last.Timestamp.IsZero():
+		return e.Timestamp.Before(last.Timestamp) ||
+			(e.Timestamp.Equal(last.Timestamp) && e.Line == last.Line)
+	}
+	return false
+}
+
+func (c *Client) pollLogs(ctx context.Context, nodeID string, opts FollowOptions, fn func(LogEntry) error) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	query := opts.LogsOptions
+	query.Offset = 0
+	if query.Lines <= 0 {
+		query.Lines = 100
+	}
+
+	var prev []string
+	attempt := 0
+	for {
+		batch, err := c.Logs(ctx, nodeID, query)
+		var apiErr *APIError
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &apiErr):
+			return err
+		case err != nil:
+			attempt++
+			if opts.OnReconnect != nil {
+				opts.OnReconnect(attempt, interval, err)
+			}
+		default:
+			attempt = 0
+			for _, line := range newLogLines(prev, batch) {
+				if err := fn(LogEntry{Line: line}); err != nil {
+					return err
+				}
+			}
+			prev = batch
+		}
+
+		if !sleepCtx(ctx, interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// newLogLines returns the lines of batch that follow its longest overlap
+// with the end of prev.
+func newLogLines(prev, batch []string) []string {
+	n := len(prev)
+	if len(batch) < n {
+		n = len(batch)
+	}
+	for k := n; k > 0; k-- {
+		if equalLines(batch[:k], prev[len(prev)-k:]) {
+			return batch[k:]
+		}
+	}
+	return batch
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
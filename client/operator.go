@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrPlanUnsupported is returned by PlanOperation when the server has no
+// plan endpoint.
+var ErrPlanUnsupported = errors.New("operation plans not supported by server")
+
+// Operator is a summary entry of api/operator/list.
+type Operator struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Author      string `json:"author"`
+	Description string `json:"description"`
+}
+
+// OperatorSchema describes an operator and the operations it supports.
+type OperatorSchema struct {
+	Name        string                     `json:"name"`
+	Version     string                     `json:"version"`
+	Description string                     `json:"description"`
+	Operations  map[string]OperationSchema `json:"operations"`
+}
+
+// OperationSchema describes the parameters and config of one operation.
+type OperationSchema struct {
+	Description string                 `json:"description"`
+	Parameters  map[string]ParamSchema `json:"parameters"`
+	Config      map[string]ParamSchema `json:"config"`
+}
+
+// Parameter types understood by the CLI.
+const (
+	ParamString   = "string"
+	ParamInt      = "int"
+	ParamBool     = "bool"
+	ParamFloat    = "float"
+	ParamDuration = "duration"
+	ParamSize     = "size"
+	ParamList     = "list"
+	ParamMap      = "map"
+)
+
+// ParamSchema describes a single operation parameter.
+type ParamSchema struct {
+	Type        string      `json:"type"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+
+	// Enum restricts the value to one of the listed values.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Min and Max bound int, float, duration and size values. They are
+	// given in the parameter's own type, e.g. "30s" or "1G".
+	Min interface{} `json:"min,omitempty"`
+	Max interface{} `json:"max,omitempty"`
+	// Pattern is a regular expression string values must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Items describes the elements of a list or the values of a map.
+	Items *ParamSchema `json:"items,omitempty"`
+}
+
+// OperatorPayload is the body sent to api/operator/trigger/<name>.
+type OperatorPayload struct {
+	Operation   string                 `json:"operation"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	Parallel    bool                   `json:"parallel"`
+	TargetNodes []string               `json:"target_nodes,omitempty"`
+}
+
+// TriggerResult is returned by a successful operator trigger.
+type TriggerResult struct {
+	JobID string `json:"job_id,omitempty"`
+}
+
+// OperationPlan is the server's preview of what an operation would change.
+type OperationPlan struct {
+	Summary string       `json:"summary,omitempty"`
+	Changes []PlanChange `json:"changes,omitempty"`
+}
+
+// PlanChange is a single change an operation would make on a node.
+type PlanChange struct {
+	Node   string      `json:"node"`
+	Action string      `json:"action"`
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// OperatorList lists the operators enabled on the cluster.
+func (c *Client) OperatorList(ctx context.Context) ([]Operator, error) {
+	var operators []Operator
+	if err := c.get(ctx, "operator/list", &operators); err != nil {
+		return nil, err
+	}
+	return operators, nil
+}
+
+// OperatorSchema fetches the schema of the named operator.
+func (c *Client) OperatorSchema(ctx context.Context, operatorName string) (*OperatorSchema, error) {
+	var schema OperatorSchema
+	if err := c.get(ctx, "operator/schema/"+url.PathEscape(operatorName), &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// TriggerOperation runs an operation of the named operator. The request
+// carries an idempotency key, so retries cannot start the operation twice.
+func (c *Client) TriggerOperation(ctx context.Context, operatorName string, payload OperatorPayload) (*TriggerResult, error) {
+	var result TriggerResult
+	if err := c.postIdempotent(ctx, "operator/trigger/"+url.PathEscape(operatorName), newIdempotencyKey(), payload, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PlanOperation asks the server what an operation would change without
+// running it. It returns ErrPlanUnsupported if the server cannot plan.
+func (c *Client) PlanOperation(ctx context.Context, operatorName string, payload OperatorPayload) (*OperationPlan, error) {
+	var plan OperationPlan
+	err := c.post(ctx, "operator/plan/"+url.PathEscape(operatorName), payload, &plan)
+
+	var apiErr *APIError
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &apiErr) && isUnsupportedStatus(apiErr.StatusCode),
+		errors.As(err, &httpErr) && isUnsupportedStatus(httpErr.StatusCode):
+		return nil, ErrPlanUnsupported
+	case err != nil:
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// isUnsupportedStatus reports whether a status means the endpoint does not
+// exist on the server, as opposed to the request being rejected.
+func isUnsupportedStatus(code int) bool {
+	switch code {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
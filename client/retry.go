@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"time"
+)
+
+// Backoff between retry rounds: retryBaseDelay doubled every round up to
+// retryMaxDelay, of which a random half is added as jitter.
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// WithRetries sets how many times a failed request is retried. Reads are
+// retried after network errors and retriable status codes; writes only
+// when the server reports it did not process them, or when they carry an
+// idempotency key. Each attempt gets its own timeout (see WithTimeout).
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// backoff returns how long to wait before the given retry round (1-based).
+func backoff(round int) time.Duration {
+	d := retryBaseDelay << (round - 1)
+	if d > retryMaxDelay || d <= 0 {
+		d = retryMaxDelay
+	}
+	return d/2 + time.Duration(mathrand.Int63n(int64(d/2)+1))
+}
+
+// retriable reports whether a request that failed with err may be sent
+// again. Idempotent requests may also be retried after network errors,
+// where it is unknown whether the server applied them.
+func retriable(err error, idempotent bool) bool {
+	var apiErr *APIError
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &apiErr):
+		return retriableStatus(apiErr.StatusCode, idempotent)
+	case errors.As(err, &httpErr):
+		return retriableStatus(httpErr.StatusCode, idempotent)
+	case errors.Is(err, context.Canceled):
+		return false
+	}
+	return idempotent
+}
+
+func retriableStatus(code int, idempotent bool) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// The server did not process the request.
+		return true
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// statusError describes a response with a retriable status code.
+func statusError(endpoint string, resp *APIResponse) error {
+	message := resp.Error
+	if message == "" {
+		message = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return &APIError{Endpoint: endpoint, Message: message, StatusCode: resp.StatusCode}
+}
+
+// logAttempts summarises a request that needed more than one attempt.
+func (c *Client) logAttempts(method, endpoint string, attempts int, err error) {
+	switch {
+	case attempts <= 1:
+	case err != nil:
+		c.logf("%s %s failed after %d attempts", method, endpoint, attempts)
+	default:
+		c.logf("%s %s succeeded after %d attempts", method, endpoint, attempts)
+	}
+}
+
+// newIdempotencyKey returns a random key identifying one logical request
+// across its retries.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
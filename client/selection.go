@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Node selection strategies, set per cluster in ClusterConfig.Strategy.
+const (
+	// StrategyOrdered tries the nodes sorted by ID. It is the default.
+	StrategyOrdered = "ordered"
+	// StrategyPreferLeader sends every request to the current leader
+	// first. Writes only go to the leader.
+	StrategyPreferLeader = "prefer-leader"
+	// StrategyRoundRobin starts every request at the next node in turn.
+	StrategyRoundRobin = "round-robin"
+	// StrategyLowestLatency probes all nodes once and tries them fastest
+	// first.
+	StrategyLowestLatency = "lowest-latency"
+	// StrategyPinned only ever talks to ClusterConfig.Via.
+	StrategyPinned = "pinned"
+)
+
+// Strategies lists the supported node selection strategies.
+var Strategies = []string{StrategyOrdered, StrategyPreferLeader, StrategyRoundRobin, StrategyLowestLatency, StrategyPinned}
+
+// target is a node a request can be sent to.
+type target struct {
+	id   string
+	addr string
+}
+
+// selector orders the nodes of a cluster according to its strategy. The
+// leader and latency ranking are looked up once per Client.
+type selector struct {
+	next uint32
+
+	leaderOnce sync.Once
+	leader     string
+
+	latencyOnce sync.Once
+	latency     []target
+}
+
+func newSelector() *selector {
+	// Start round-robin at a random node so short-lived processes do not
+	// all hit the same one.
+	return &selector{next: rand.Uint32()}
+}
+
+// strategy returns the configured strategy, pinned if only Via is set.
+func (c *Client) strategy() string {
+	switch {
+	case c.cluster.Strategy != "":
+		return c.cluster.Strategy
+	case c.cluster.Via != "":
+		return StrategyPinned
+	}
+	return StrategyOrdered
+}
+
+// targets returns the nodes to try for a request, in order. Writes are
+// only ever sent to the first one.
+func (c *Client) targets(ctx context.Context, write bool) ([]target, error) {
+	ordered := c.orderedTargets()
+	if len(ordered) == 0 {
+		return nil, fmt.Errorf("cluster %q has no nodes", c.cluster.Name)
+	}
+
+	switch strategy := c.strategy(); strategy {
+	case StrategyOrdered:
+		return ordered, nil
+	case StrategyPinned:
+		addr, ok := c.cluster.Nodes[c.cluster.Via]
+		if !ok {
+			return nil, fmt.Errorf("pinned node %q is not part of cluster %q", c.cluster.Via, c.cluster.Name)
+		}
+		return []target{{id: c.cluster.Via, addr: addr}}, nil
+	case StrategyRoundRobin:
+		start := int(atomic.AddUint32(&c.selector.next, 1) % uint32(len(ordered)))
+		return append(ordered[start:], ordered[:start]...), nil
+	case StrategyLowestLatency:
+		return c.byLatency(ctx, ordered), nil
+	case StrategyPreferLeader:
+		leader := c.leaderID(ctx)
+		if leader == "" {
+			c.logf("leader unknown, falling back to %s order", StrategyOrdered)
+			return ordered, nil
+		}
+		result := []target{{id: leader, addr: c.cluster.Nodes[leader]}}
+		if !write {
+			for _, t := range ordered {
+				if t.id != leader {
+					result = append(result, t)
+				}
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown node selection strategy %q (want one of %s)", strategy, strings.Join(Strategies, ", "))
+	}
+}
+
+func (c *Client) orderedTargets() []target {
+	ids := make([]string, 0, len(c.cluster.Nodes))
+	for id := range c.cluster.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	targets := make([]target, len(ids))
+	for i, id := range ids {
+		targets[i] = target{id: id, addr: c.cluster.Nodes[id]}
+	}
+	return targets
+}
+
+// leaderID asks the cluster for its leader and maps it to a configured
+// node ID, by ID or by address. It returns "" if that is not possible.
+func (c *Client) leaderID(ctx context.Context) string {
+	c.selector.leaderOnce.Do(func() {
+		var leader Leader
+		if err := c.getFrom(ctx, c.orderedTargets(), "leader", &leader); err != nil {
+			c.logf("looking up leader: %v", err)
+			return
+		}
+		for id, addr := range c.cluster.Nodes {
+			if id == leader.ID || (leader.Address != "" && addr == leader.Address) {
+				c.selector.leader = id
+				return
+			}
+		}
+		c.logf("leader %s is not part of cluster %q", leader.ID, c.cluster.Name)
+	})
+	return c.selector.leader
+}
+
+// byLatency probes every node concurrently and orders them healthy and
+// fastest first.
+func (c *Client) byLatency(ctx context.Context, ordered []target) []target {
+	c.selector.latencyOnce.Do(func() {
+		probes := make([]HealthProbe, len(ordered))
+		var wg sync.WaitGroup
+		for i, t := range ordered {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				probes[i] = c.ProbeHealth(ctx, id)
+			}(i, t.id)
+		}
+		wg.Wait()
+
+		sort.SliceStable(probes, func(i, j int) bool {
+			if probes[i].Healthy != probes[j].Healthy {
+				return probes[i].Healthy
+			}
+			return probes[i].Latency < probes[j].Latency
+		})
+		for _, probe := range probes {
+			c.selector.latency = append(c.selector.latency, target{id: probe.Node, addr: probe.Address})
+			if probe.Healthy {
+				c.logf("latency of %s: %s", probe.Node, probe.Latency.Round(time.Microsecond))
+			} else {
+				c.logf("latency of %s: unhealthy (%s)", probe.Node, probe.Error)
+			}
+		}
+	})
+	return c.selector.latency
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// backupPollInterval is how often a running backup is polled for progress.
+const backupPollInterval = time.Second
+
+func createBackup(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	c := newClient(cluster)
+
+	backupName := args[0]
+	backup, err := c.BackupCreate(cmd.Context(), backupName)
+	if err != nil {
+		fmt.Printf("Failed to create backup: %v\n", err)
+		return
+	}
+
+	if !backup.Done() {
+		if backupNoWait {
+			fmt.Printf("Backup '%s' started (%s)\n", backupName, backup.State)
+			fmt.Printf("Use 'gocluster backup describe %s' to follow its progress\n", backupName)
+			return
+		}
+		if backup, err = waitForBackup(cmd.Context(), c, backup); err != nil {
+			fmt.Printf("Error waiting for backup: %v\n", err)
+			return
+		}
+	}
+
+	if strings.EqualFold(backup.State, client.BackupFailed) {
+		fmt.Printf("Failed to create backup: %s\n", backup.Error)
+		return
+	}
+	if backup.Size > 0 {
+		fmt.Printf("Backup '%s' created successfully (%s)\n", backupName, backup.Size)
+	} else {
+		fmt.Printf("Backup '%s' created successfully\n", backupName)
+	}
+}
+
+// waitForBackup polls a running backup until it finishes, reporting its
+// progress on a single updating line when stdout is a terminal.
+func waitForBackup(ctx context.Context, c *client.Client, backup *client.Backup) (*client.Backup, error) {
+	tty := isTerminal(os.Stdout)
+	lastReported := -1.0
+
+	for !backup.Done() {
+		progress := fmt.Sprintf("Backup '%s': %s %3.0f%%", backup.Name, backup.State, backup.Progress)
+		if backup.Size > 0 {
+			progress += fmt.Sprintf(" (%s)", backup.Size)
+		}
+		switch {
+		case tty:
+			fmt.Printf("\r\033[K%s", progress)
+		case backup.Progress != lastReported:
+			fmt.Println(progress)
+		}
+		lastReported = backup.Progress
+
+		select {
+		case <-ctx.Done():
+			if tty {
+				fmt.Println()
+			}
+			return nil, ctx.Err()
+		case <-time.After(backupPollInterval):
+		}
+
+		next, err := c.BackupDescribe(ctx, backup.Name)
+		if err != nil {
+			if tty {
+				fmt.Println()
+			}
+			return nil, err
+		}
+		backup = next
+	}
+	if tty {
+		fmt.Print("\r\033[K")
+	}
+	return backup, nil
+}
+
+func listBackups(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	backups, err := newClient(cluster).BackupList(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error listing backups: %v\n", err)
+		return
+	}
+
+	l := &listing{
+		data:       backups,
+		kind:       "backups",
+		header:     []string{"Name", "Size", "Created At", "State"},
+		wideHeader: []string{"Bytes", "Nodes"},
+	}
+	for _, b := range backups {
+		l.rows = append(l.rows, []string{b.Name, b.Size.String(), humanizeTimestamp(b.CreatedAt), b.State})
+		l.wideRows = append(l.wideRows, []string{fmt.Sprint(uint64(b.Size)), strings.Join(b.Nodes, ",")})
+		l.names = append(l.names, b.Name)
+	}
+	printListing(l)
+}
+
+func describeBackup(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	backup, err := newClient(cluster).BackupDescribe(cmd.Context(), args[0])
+	if err != nil {
+		fmt.Printf("Error describing backup: %v\n", err)
+		return
+	}
+
+	l := &listing{
+		data:   backup,
+		header: []string{"Field", "Value"},
+		rows: [][]string{
+			{"Name", backup.Name},
+			{"State", backup.State},
+			{"Progress", fmt.Sprintf("%.0f%%", backup.Progress)},
+			{"Size", fmt.Sprintf("%s (%d bytes)", backup.Size, uint64(backup.Size))},
+			{"Created At", humanizeTimestamp(backup.CreatedAt)},
+			{"Completed At", humanizeTimestamp(backup.CompletedAt)},
+			{"Nodes", strings.Join(backup.Nodes, ", ")},
+			{"Error", backup.Error},
+		},
+		names: []string{backup.Name},
+	}
+	printListing(l)
+}
+
+func restoreBackup(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	backupName := args[0]
+	if !backupYes && !confirm(cmd.Context(), fmt.Sprintf("Restore backup '%s' on cluster '%s'? This overwrites the current cluster state.", backupName, config.SelectedCluster)) {
+		fmt.Println("Restore cancelled")
+		return
+	}
+
+	if err := newClient(cluster).BackupRestore(cmd.Context(), backupName); err != nil {
+		fmt.Printf("Failed to restore backup: %v\n", err)
+		return
+	}
+	fmt.Printf("Backup '%s' restored successfully\n", backupName)
+}
+
+func deleteBackup(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	backupName := args[0]
+	if !backupYes && !confirm(cmd.Context(), fmt.Sprintf("Delete backup '%s' from cluster '%s'?", backupName, config.SelectedCluster)) {
+		fmt.Println("Delete cancelled")
+		return
+	}
+
+	if err := newClient(cluster).BackupDelete(cmd.Context(), backupName); err != nil {
+		fmt.Printf("Failed to delete backup: %v\n", err)
+		return
+	}
+	fmt.Printf("Backup '%s' deleted successfully\n", backupName)
+}
+
+// humanizeTimestamp renders an RFC3339 timestamp as "3 hours ago", leaving
+// other values untouched.
+func humanizeTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return humanize.Time(t)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+)
+
+// cachePath returns the file holding the cache entry key of a cluster in
+// the user cache directory, e.g. ~/.cache/gocluster/<cluster>/<key>.json.
+func cachePath(cluster, key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gocluster", cluster, key+".json"), nil
+}
+
+// readCache decodes the cache entry into out if it is younger than ttl, or
+// of any age if ttl is zero.
+func readCache(cluster, key string, ttl time.Duration, out interface{}) bool {
+	path, err := cachePath(cluster, key)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || (ttl > 0 && time.Since(info.ModTime()) > ttl) {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+// writeCache stores v as a cache entry. Errors are ignored since the cache
+// only saves round trips to the cluster.
+func writeCache(cluster, key string, v interface{}) {
+	path, err := cachePath(cluster, key)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	// Concurrent completions must never read a partial entry.
+	writeFileAtomic(path, data)
+}
+
+// schemaCacheTTL returns how long cached operator schemas are trusted.
+func schemaCacheTTL() time.Duration {
+	if config.SchemaCacheTTL > 0 {
+		return config.SchemaCacheTTL
+	}
+	return defaultSchemaCacheTTL
+}
+
+// schemaCacheKey names the cache entry of one version of an operator's
+// schema. The schema of a version never changes, so entries do not expire.
+func schemaCacheKey(operatorName, version string) string {
+	return "schema-" + url.PathEscape(operatorName) + "@" + url.PathEscape(version)
+}
+
+// fetchOperatorSchema returns the schema of the named operator of the
+// selected cluster. The cached schema of the operator version the cluster
+// lists is used; the operator list itself is trusted for the TTL and
+// fetched again with --refresh, which also fetches the schema again. If
+// the cluster cannot be reached the schema of the last listed version is
+// used instead, and with --offline only the cache is consulted.
+func fetchOperatorSchema(ctx context.Context, c *client.Client, operatorName string) (*client.OperatorSchema, error) {
+	var cached client.OperatorSchema
+	if schemaOffline {
+		if !readCachedSchema(operatorName, 0, &cached) {
+			return nil, fmt.Errorf("no cached schema for operator '%s' of cluster '%s'", operatorName, config.SelectedCluster)
+		}
+		return &cached, nil
+	}
+
+	if !schemaRefresh && readCachedSchema(operatorName, schemaCacheTTL(), &cached) {
+		return &cached, nil
+	}
+	operators, err := c.OperatorList(ctx)
+	if err == nil {
+		writeCache(config.SelectedCluster, "operators", operators)
+		if version, ok := listedVersion(operators, operatorName); ok && !schemaRefresh &&
+			readCache(config.SelectedCluster, schemaCacheKey(operatorName, version), 0, &cached) {
+			return &cached, nil
+		}
+	}
+
+	var schema *client.OperatorSchema
+	if err == nil || !unreachable(ctx, err) {
+		schema, err = c.OperatorSchema(ctx, operatorName)
+	}
+	if err != nil {
+		if unreachable(ctx, err) && readCachedSchema(operatorName, 0, &cached) {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using cached schema of operator '%s'\n", err, operatorName)
+			return &cached, nil
+		}
+		return nil, err
+	}
+	writeCache(config.SelectedCluster, schemaCacheKey(operatorName, schema.Version), schema)
+	return schema, nil
+}
+
+// readCachedSchema decodes the cached schema of the operator version in
+// the operator list cached within ttl, or of any age if ttl is zero.
+func readCachedSchema(operatorName string, ttl time.Duration, out *client.OperatorSchema) bool {
+	var operators []client.Operator
+	if !readCache(config.SelectedCluster, "operators", ttl, &operators) {
+		return false
+	}
+	version, ok := listedVersion(operators, operatorName)
+	return ok && readCache(config.SelectedCluster, schemaCacheKey(operatorName, version), 0, out)
+}
+
+// unreachable reports whether err means no node answered. A node
+// rejecting the request knows better than the cache.
+func unreachable(ctx context.Context, err error) bool {
+	var apiErr *client.APIError
+	var httpErr *client.HTTPError
+	return ctx.Err() == nil && !errors.As(err, &apiErr) && !errors.As(err, &httpErr)
+}
+
+// listedVersion returns the version of the named operator in operators.
+func listedVersion(operators []client.Operator, name string) (string, bool) {
+	for _, op := range operators {
+		if op.Name == name {
+			return op.Version, true
+		}
+	}
+	return "", false
+}
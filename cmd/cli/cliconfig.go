@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configVersion is the newest .gocluster.yaml schema this build
+// understands. Version 1 moved the CLI settings into the cli section;
+// files without a version use the top-level keys.
+const configVersion = 1
+
+// Defaults for settings the config does not set.
+const (
+	defaultTimeout        = 10
+	defaultRetries        = 3
+	defaultSchemaCacheTTL = time.Hour
+)
+
+// Config is the content of .gocluster.yaml.
+type Config struct {
+	Version  int                             `mapstructure:"version"`
+	CLI      CLIConfig                       `mapstructure:"cli"`
+	Clusters map[string]client.ClusterConfig `mapstructure:"clusters"`
+	Contexts map[string]ContextConfig        `mapstructure:"contexts"`
+
+	// The settings the commands use. They are read from the top-level keys
+	// of unversioned configs, then overridden by the cli section and
+	// defaulted by resolveConfig.
+	SelectedCluster string        `mapstructure:"selected_cluster"`
+	Timeout         int           `mapstructure:"timeout"`
+	Retries         int           `mapstructure:"retries"`
+	SchemaCacheTTL  time.Duration `mapstructure:"schema_cache_ttl"`
+}
+
+// CLIConfig is the cli section of the config.
+type CLIConfig struct {
+	// DefaultCluster is the cluster commands run against.
+	DefaultCluster string `mapstructure:"default_cluster"`
+	// CurrentContext is the active context. Its cluster replaces
+	// DefaultCluster.
+	CurrentContext string `mapstructure:"current_context"`
+	// Timeout is the timeout of a single request in seconds.
+	Timeout int `mapstructure:"timeout"`
+	// Retries is how often failed requests are retried.
+	Retries int `mapstructure:"retries"`
+	// SchemaCacheTTL is how long cached operator schemas are trusted.
+	SchemaCacheTTL time.Duration `mapstructure:"schema_cache_ttl"`
+}
+
+// resolveConfig fills the settings of cfg from the cli section, the
+// top-level keys and the defaults, in that order.
+func resolveConfig(v *viper.Viper, cfg *Config) error {
+	if cfg.Version > configVersion {
+		return fmt.Errorf("config version %d is newer than this gocluster supports (%d)", cfg.Version, configVersion)
+	}
+
+	if v.IsSet("cli.default_cluster") {
+		cfg.SelectedCluster = cfg.CLI.DefaultCluster
+	}
+	switch {
+	case v.IsSet("cli.timeout"):
+		cfg.Timeout = cfg.CLI.Timeout
+	case !v.IsSet("timeout"):
+		cfg.Timeout = defaultTimeout
+	}
+	switch {
+	case v.IsSet("cli.retries"):
+		cfg.Retries = cfg.CLI.Retries
+	case !v.IsSet("retries"):
+		cfg.Retries = defaultRetries
+	}
+	if v.IsSet("cli.schema_cache_ttl") {
+		cfg.SchemaCacheTTL = cfg.CLI.SchemaCacheTTL
+	}
+	return nil
+}
+
+// configProblem is an issue found by 'config validate'.
+type configProblem struct {
+	line    int
+	message string
+}
+
+func validateConfig(cmd *cobra.Command, args []string) {
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		var notFound viper.ConfigFileNotFoundError
+		if err := viper.ReadInConfig(); errors.As(err, &notFound) {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		path = viper.ConfigFileUsed()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	problems, err := checkConfig(data)
+	if err != nil {
+		fmt.Printf("%s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if len(problems) == 0 {
+		fmt.Printf("%s is valid\n", path)
+		return
+	}
+	for _, p := range problems {
+		fmt.Printf("%s:%d: %s\n", path, p.line, p.message)
+	}
+	fmt.Printf("%d problem(s) found\n", len(problems))
+	os.Exit(1)
+}
+
+// checkConfig reports unknown keys, values of the wrong type and
+// references to unknown clusters, nodes and strategies in a config file.
+// The error is only set if the file is not valid YAML.
+func checkConfig(data []byte) ([]configProblem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return []configProblem{{line: 1, message: "config is empty"}}, nil
+	}
+	root := doc.Content[0]
+
+	var problems []configProblem
+	checkConfigNode(root, reflect.TypeOf(Config{}), "", &problems)
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	var cfg Config
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := v.Unmarshal(&cfg); err != nil {
+		// The references below can only be checked in a well-typed file.
+		if len(problems) > 0 {
+			return problems, nil
+		}
+		return nil, err
+	}
+	if version := mappingValue(root, "version"); version != nil && cfg.Version > configVersion {
+		problems = append(problems, configProblem{version.Line, fmt.Sprintf("version %d is newer than this gocluster supports (%d)", cfg.Version, configVersion)})
+	}
+	for _, key := range []string{"selected_cluster", "cli.default_cluster"} {
+		node := mappingValue(root, strings.Split(key, ".")...)
+		if node != nil && node.Value != "" {
+			if _, ok := cfg.Clusters[node.Value]; !ok {
+				problems = append(problems, configProblem{node.Line, fmt.Sprintf("%s: unknown cluster '%s'", key, node.Value)})
+			}
+		}
+	}
+	if node := mappingValue(root, "cli", "current_context"); node != nil && node.Value != "" {
+		if _, ok := cfg.Contexts[node.Value]; !ok {
+			problems = append(problems, configProblem{node.Line, fmt.Sprintf("cli.current_context: unknown context '%s'", node.Value)})
+		}
+	}
+	for _, name := range sortedKeys(cfg.Contexts) {
+		ctx := cfg.Contexts[name]
+		if ctx.Output != "" {
+			if _, err := newPrinter(ctx.Output); err != nil {
+				problems = append(problems, configProblem{mappingValue(root, "contexts", name, "output").Line, fmt.Sprintf("contexts.%s.output: %v", name, err)})
+			}
+		}
+		node := mappingValue(root, "contexts", name, "cluster")
+		cluster, ok := cfg.Clusters[ctx.Cluster]
+		switch {
+		case node == nil:
+			problems = append(problems, configProblem{mappingValue(root, "contexts", name).Line, fmt.Sprintf("contexts.%s: no cluster set", name)})
+		case !ok:
+			problems = append(problems, configProblem{node.Line, fmt.Sprintf("contexts.%s.cluster: unknown cluster '%s'", name, ctx.Cluster)})
+		default:
+			for _, id := range ctx.Nodes {
+				if _, ok := cluster.Nodes[id]; !ok {
+					node := mappingValue(root, "contexts", name, "nodes")
+					problems = append(problems, configProblem{node.Line, fmt.Sprintf("contexts.%s.nodes: unknown node '%s' of cluster '%s'", name, id, ctx.Cluster)})
+				}
+			}
+		}
+	}
+	for _, name := range sortedKeys(cfg.Clusters) {
+		cluster := cfg.Clusters[name]
+		if node := mappingValue(root, "clusters", name, "strategy"); node != nil && cluster.Strategy != "" && !containsString(client.Strategies, cluster.Strategy) {
+			problems = append(problems, configProblem{node.Line, fmt.Sprintf("clusters.%s.strategy: unknown strategy '%s' (want one of %s)", name, cluster.Strategy, strings.Join(client.Strategies, ", "))})
+		}
+		if node := mappingValue(root, "clusters", name, "via"); node != nil && cluster.Via != "" {
+			if _, ok := cluster.Nodes[cluster.Via]; !ok {
+				problems = append(problems, configProblem{node.Line, fmt.Sprintf("clusters.%s.via: unknown node '%s'", name, cluster.Via)})
+			}
+		}
+	}
+	return problems, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkConfigNode checks node against the mapstructure layout of t. Keys
+// are matched case-insensitively, as viper does.
+func checkConfigNode(node *yaml.Node, t reflect.Type, path string, problems *[]configProblem) {
+	mismatch := func(want string) {
+		*problems = append(*problems, configProblem{node.Line, fmt.Sprintf("%s: expected %s, got %s", displayPath(path), want, yamlKind(node))})
+	}
+
+	switch {
+	case t == durationType:
+		if node.Kind != yaml.ScalarNode {
+			mismatch("a duration")
+		} else if _, err := time.ParseDuration(node.Value); err != nil && node.Tag != "!!int" {
+			mismatch("a duration such as 30m")
+		}
+	case t.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			mismatch("a mapping")
+			return
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			fields[t.Field(i).Tag.Get("mapstructure")] = t.Field(i).Type
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[strings.ToLower(key.Value)]
+			if !ok {
+				*problems = append(*problems, configProblem{key.Line, fmt.Sprintf("unknown key '%s'", joinPath(path, key.Value))})
+				continue
+			}
+			checkConfigNode(value, field, joinPath(path, key.Value), problems)
+		}
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			mismatch("a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkConfigNode(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), problems)
+		}
+	case t.Kind() == reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			mismatch("a list")
+			return
+		}
+		for i, item := range node.Content {
+			checkConfigNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case t.Kind() == reflect.Interface:
+		// Any value, e.g. operator params checked against their schema.
+	case t.Kind() == reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			mismatch("a string")
+		}
+	case t.Kind() == reflect.Int:
+		var n int
+		if node.Kind != yaml.ScalarNode || node.Decode(&n) != nil {
+			mismatch("an integer")
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// yamlKind describes a node for type errors.
+func yamlKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// mappingValue returns the value at the key path below node, matching
+// keys case-insensitively, or nil.
+func mappingValue(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				next = node.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionCacheTTL is how long the operator list is reused.
+	completionCacheTTL = 5 * time.Minute
+	// completionTimeout bounds requests made while completing so that
+	// pressing TAB never hangs on an unreachable cluster.
+	completionTimeout = 2 * time.Second
+)
+
+// completeClusters completes cluster names from the config.
+func completeClusters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sortedKeys(config.Clusters), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNodes completes node IDs of the selected cluster, including the
+// comma-separated lists taken by --nodes.
+func completeNodes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	chosen := map[string]bool{}
+	for _, node := range strings.Split(prefix, ",") {
+		chosen[node] = true
+	}
+
+	var completions []string
+	for _, node := range sortedKeys(cluster.Nodes) {
+		if !chosen[node] {
+			completions = append(completions, prefix+node)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOperators completes the first argument with the operators
+// enabled on the selected cluster.
+func completeOperators(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return operatorNames(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTrigger completes the operator and then the operation of
+// 'operator trigger'.
+func completeTrigger(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return operatorNames(cmd), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		schema := completionSchema(cmd, args[0])
+		if schema == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return sortedKeys(schema.Operations), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeParamKeys returns a completion function offering "name=" for
+// every parameter of the operation that was not given yet. section selects
+// the operation's params or its config.
+func completeParamKeys(section string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) < 2 || strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		schema := completionSchema(cmd, args[0])
+		if schema == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		opSchema, ok := schema.Operations[args[1]]
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		params := opSchema.Parameters
+		if section == "config" {
+			params = opSchema.Config
+		}
+		given, _ := cmd.Flags().GetStringToString(section)
+
+		var completions []string
+		for _, name := range sortedKeys(params) {
+			if _, ok := given[name]; ok {
+				continue
+			}
+			completions = append(completions, name+"=\t"+params[name].Description)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// operatorNames lists the operators of the selected cluster, from the
+// completion cache if possible.
+func operatorNames(cmd *cobra.Command) []string {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		return nil
+	}
+
+	var operators []client.Operator
+	if !readCache(config.SelectedCluster, "operators", completionCacheTTL, &operators) {
+		ctx, cancel := completionContext(cmd)
+		defer cancel()
+		if operators, err = newClient(cluster).OperatorList(ctx); err != nil {
+			return nil
+		}
+		writeCache(config.SelectedCluster, "operators", operators)
+	}
+
+	names := make([]string, len(operators))
+	for i, op := range operators {
+		names[i] = op.Name
+	}
+	return names
+}
+
+// completionSchema returns the schema of the named operator, from the
+// schema cache if possible.
+func completionSchema(cmd *cobra.Command, operatorName string) *client.OperatorSchema {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := completionContext(cmd)
+	defer cancel()
+	schema, err := fetchOperatorSchema(ctx, newClient(cluster), operatorName)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+func completionContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, completionTimeout)
+}
+
+// completeOutput completes -o, leaving the cursor after "go-template=" and
+// similar formats that take an argument.
+func completeOutput(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, len(outputFormats))
+	for i, format := range outputFormats {
+		completions[i] = strings.TrimSuffix(format, "...")
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Flags of 'context create'
+var (
+	contextCluster     string
+	contextNodes       []string
+	contextOutput      string
+	contextTimeout     int
+	contextTokenEnv    string
+	contextUsername    string
+	contextPasswordEnv string
+)
+
+// ContextConfig bundles a cluster with the settings used with it, like a
+// kubeconfig context. Unset fields fall back to the global settings.
+type ContextConfig struct {
+	Cluster string `mapstructure:"cluster" json:"cluster" yaml:"cluster"`
+	// Nodes is the default for --nodes.
+	Nodes []string `mapstructure:"nodes" json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	// Output is the default for --output.
+	Output string `mapstructure:"output" json:"output,omitempty" yaml:"output,omitempty"`
+	// Timeout overrides cli.timeout, in seconds.
+	Timeout     int               `mapstructure:"timeout" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Credentials CredentialsConfig `mapstructure:"credentials" json:"-" yaml:"-"`
+	// Operators holds default params and config per operator, applied
+	// below the environment and above the schema defaults.
+	Operators map[string]OperatorDefaults `mapstructure:"operators" json:"operators,omitempty" yaml:"operators,omitempty"`
+}
+
+// CredentialsConfig authenticates the requests of a context. Secrets are
+// best read from the environment through the *_env keys.
+type CredentialsConfig struct {
+	Token       string `mapstructure:"token"`
+	TokenEnv    string `mapstructure:"token_env"`
+	Username    string `mapstructure:"username"`
+	Password    string `mapstructure:"password"`
+	PasswordEnv string `mapstructure:"password_env"`
+}
+
+// OperatorDefaults are values sent with every operation of an operator.
+type OperatorDefaults struct {
+	Params map[string]interface{} `mapstructure:"params" json:"params,omitempty" yaml:"params,omitempty"`
+	Config map[string]interface{} `mapstructure:"config" json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// credentials resolves the configured credentials, reading the *_env keys.
+func (c CredentialsConfig) credentials() client.Credentials {
+	creds := client.Credentials{Token: c.Token, Username: c.Username, Password: c.Password}
+	if c.TokenEnv != "" {
+		creds.Token = os.Getenv(c.TokenEnv)
+	}
+	if c.PasswordEnv != "" {
+		creds.Password = os.Getenv(c.PasswordEnv)
+	}
+	return creds
+}
+
+// auth describes the kind of credentials without revealing them.
+func (c CredentialsConfig) auth() string {
+	switch {
+	case c.Token != "" || c.TokenEnv != "":
+		return "token"
+	case c.Username != "":
+		return "basic"
+	}
+	return ""
+}
+
+// currentContext returns the active context, or nil. A context is not
+// active while --cluster or GOCLUSTER_CLUSTER select another cluster, so
+// its credentials never reach that cluster.
+func currentContext() *ContextConfig {
+	if config.CLI.CurrentContext == "" {
+		return nil
+	}
+	ctx, ok := config.Contexts[config.CLI.CurrentContext]
+	if !ok {
+		return nil
+	}
+	if cluster := overrides.GetString("cluster"); cluster != "" && cluster != ctx.Cluster {
+		return nil
+	}
+	return &ctx
+}
+
+// applyContext makes the current context's settings the defaults of this
+// invocation. Flags given on the command line still win. A current context
+// missing from the config is ignored with a warning, so that 'context use'
+// and 'use' can still select another one.
+func applyContext() {
+	if config.CLI.CurrentContext == "" {
+		return
+	}
+	if _, ok := config.Contexts[config.CLI.CurrentContext]; !ok {
+		fmt.Fprintf(os.Stderr, "Warning: current context '%s' not found in configuration; ignoring it\n", config.CLI.CurrentContext)
+		return
+	}
+	ctx := currentContext()
+	if ctx == nil {
+		return
+	}
+
+	config.SelectedCluster = ctx.Cluster
+	if ctx.Timeout > 0 {
+		config.Timeout = ctx.Timeout
+	}
+	if len(ctx.Nodes) > 0 && !rootCmd.PersistentFlags().Changed("nodes") {
+		targetNodes = ctx.Nodes
+	}
+	if ctx.Output != "" && !rootCmd.PersistentFlags().Changed("output") {
+		outputFormat = ctx.Output
+	}
+}
+
+// contextCredentials returns the credentials of the current context.
+func contextCredentials() client.Credentials {
+	if ctx := currentContext(); ctx != nil {
+		return ctx.Credentials.credentials()
+	}
+	return client.Credentials{}
+}
+
+// contextLayer returns the current context's defaults for one section of
+// an operator's payload. The defaults apply to every operation of the
+// operator, so only the names in the operation's schema are taken. Names
+// are matched case-insensitively since viper lowercases map keys.
+func contextLayer(operatorName, section string, schema map[string]client.ParamSchema) paramLayer {
+	layer := paramLayer{source: sourceContext, values: map[string]interface{}{}}
+	ctx := currentContext()
+	if ctx == nil {
+		return layer
+	}
+	defaults := ctx.Operators[strings.ToLower(operatorName)]
+	values := defaults.Params
+	if section == "config" {
+		values = defaults.Config
+	}
+	for key, value := range values {
+		for name := range schema {
+			if strings.EqualFold(name, key) {
+				layer.values[name] = value
+			}
+		}
+	}
+	return layer
+}
+
+// contextSummary is how a context is listed; credentials are reduced to
+// their kind.
+type contextSummary struct {
+	Name          string `json:"name" yaml:"name"`
+	Current       bool   `json:"current" yaml:"current"`
+	Auth          string `json:"auth,omitempty" yaml:"auth,omitempty"`
+	ContextConfig `yaml:",inline"`
+}
+
+func listContexts(cmd *cobra.Command, args []string) {
+	l := &listing{
+		kind:       "contexts",
+		header:     []string{"Current", "Name", "Cluster", "Nodes", "Output"},
+		wideHeader: []string{"Timeout", "Auth", "Operators"},
+	}
+	contexts := make([]contextSummary, 0, len(config.Contexts))
+	for _, name := range sortedKeys(config.Contexts) {
+		ctx := config.Contexts[name]
+		current := name == config.CLI.CurrentContext
+		contexts = append(contexts, contextSummary{Name: name, Current: current, Auth: ctx.Credentials.auth(), ContextConfig: ctx})
+
+		marker, nodes, timeout := "", "all", ""
+		if current {
+			marker = "*"
+		}
+		if len(ctx.Nodes) > 0 {
+			nodes = strings.Join(ctx.Nodes, ",")
+		}
+		if ctx.Timeout > 0 {
+			timeout = strconv.Itoa(ctx.Timeout) + "s"
+		}
+		l.rows = append(l.rows, []string{marker, name, ctx.Cluster, nodes, ctx.Output})
+		l.wideRows = append(l.wideRows, []string{timeout, ctx.Credentials.auth(), strings.Join(sortedKeys(ctx.Operators), ",")})
+		l.names = append(l.names, name)
+	}
+	l.data = contexts
+	printListing(l)
+}
+
+func showCurrentContext(cmd *cobra.Command, args []string) {
+	if config.CLI.CurrentContext == "" {
+		fmt.Println("No context selected. Use 'gocluster context use <context_name>' to select one.")
+		return
+	}
+	fmt.Println(config.CLI.CurrentContext)
+}
+
+func useContext(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	ctx := mappingValue(f.root, "contexts", name)
+	if ctx == nil {
+		fmt.Printf("Context '%s' not found. Available contexts:\n", name)
+		for _, name := range sortedKeys(config.Contexts) {
+			fmt.Printf("- %s\n", name)
+		}
+		return
+	}
+	setMappingValue(ensureMapping(f.root, "cli"), "current_context", stringNode(name))
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Now using context: %s (cluster %s)\n", name, mappingValue(ctx, "cluster").Value)
+}
+
+func createContext(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	if mappingValue(f.root, "contexts", name) != nil {
+		fmt.Printf("Error: context '%s' already exists\n", name)
+		return
+	}
+
+	ctx := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(ctx, "cluster", stringNode(contextCluster))
+	if len(contextNodes) > 0 {
+		nodes := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, node := range contextNodes {
+			nodes.Content = append(nodes.Content, stringNode(node))
+		}
+		setMappingValue(ctx, "nodes", nodes)
+	}
+	if contextOutput != "" {
+		setMappingValue(ctx, "output", stringNode(contextOutput))
+	}
+	if contextTimeout > 0 {
+		setMappingValue(ctx, "timeout", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(contextTimeout)})
+	}
+	if contextTokenEnv != "" || contextUsername != "" {
+		creds := &yaml.Node{Kind: yaml.MappingNode}
+		if contextTokenEnv != "" {
+			setMappingValue(creds, "token_env", stringNode(contextTokenEnv))
+		}
+		if contextUsername != "" {
+			setMappingValue(creds, "username", stringNode(contextUsername))
+		}
+		if contextPasswordEnv != "" {
+			setMappingValue(creds, "password_env", stringNode(contextPasswordEnv))
+		}
+		setMappingValue(ctx, "credentials", creds)
+	}
+	setMappingValue(ensureMapping(f.root, "contexts"), name, ctx)
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Context '%s' created for cluster '%s'\n", name, contextCluster)
+}
+
+func deleteContext(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	if !deleteMappingKey(ensureMapping(f.root, "contexts"), name) {
+		fmt.Printf("Error: context '%s' not found\n", name)
+		return
+	}
+	wasCurrent := false
+	if node := mappingValue(f.root, "cli", "current_context"); node != nil && strings.EqualFold(node.Value, name) {
+		node.Value, wasCurrent = "", true
+	}
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Context '%s' deleted\n", name)
+	if wasCurrent {
+		fmt.Println("It was the current context; commands now use the default cluster.")
+	}
+}
+
+// completeContexts completes context names from the config.
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sortedKeys(config.Contexts), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of 'operator wait' when the job does not report its own.
+const (
+	exitJobFailed    = 1
+	exitJobCancelled = 2
+	exitJobTimeout   = 3
+)
+
+func operatorStatus(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	job, err := newClient(cluster).Job(cmd.Context(), args[0])
+	if err != nil {
+		fmt.Printf("Error fetching job: %v\n", err)
+		return
+	}
+	printJob(job)
+}
+
+func operatorWait(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	os.Exit(waitForJob(cmd.Context(), newClient(cluster), args[0]))
+}
+
+// waitForJob blocks until the job finishes or --timeout passes, prints its
+// final status and returns the exit code the command should end with.
+func waitForJob(ctx context.Context, c *client.Client, jobID string) int {
+	if jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, jobTimeout)
+		defer cancel()
+	}
+
+	tty := isTerminal(os.Stdout) && isTableOutput()
+	job, err := c.WaitJob(ctx, jobID, jobInterval, func(job *client.Job) {
+		if tty && !job.Done() {
+			fmt.Printf("\r\033[KJob %s: %s, %s", job.ID, job.State, jobProgress(job))
+		}
+	})
+	if tty {
+		fmt.Print("\r\033[K")
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Printf("Timed out after %s waiting for job %s\n", jobTimeout, jobID)
+		if job != nil {
+			printJob(job)
+		}
+		return exitJobTimeout
+	case err != nil:
+		fmt.Printf("Error waiting for job: %v\n", err)
+		return exitJobFailed
+	}
+
+	printJob(job)
+	return jobExitCode(job)
+}
+
+// jobExitCode maps a finished job to a process exit code, preferring the
+// code reported by the job itself.
+func jobExitCode(job *client.Job) int {
+	if job.ExitCode != 0 {
+		return job.ExitCode
+	}
+	switch strings.ToLower(job.State) {
+	case client.JobFailed, "error":
+		return exitJobFailed
+	case client.JobCancelled, "canceled":
+		return exitJobCancelled
+	}
+	return 0
+}
+
+// jobProgress summarises how many nodes finished, e.g. "3/5 nodes done".
+func jobProgress(job *client.Job) string {
+	done := 0
+	for _, node := range job.Nodes {
+		if node.Done() {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d nodes done", done, len(job.Nodes))
+}
+
+func printJob(job *client.Job) {
+	if isTableOutput() {
+		fmt.Printf("Job:       %s\n", job.ID)
+		fmt.Printf("Operation: %s %s\n", job.Operator, job.Operation)
+		fmt.Printf("State:     %s (%s)\n", job.State, jobProgress(job))
+		if !job.CreatedAt.IsZero() {
+			fmt.Printf("Started:   %s\n", humanize.Time(job.CreatedAt))
+		}
+		if job.Error != "" {
+			fmt.Printf("Error:     %s\n", job.Error)
+		}
+		fmt.Println()
+	}
+
+	l := &listing{
+		data:       job,
+		header:     []string{"Node", "State", "Progress", "Error"},
+		wideHeader: []string{"Result"},
+	}
+	for _, node := range job.Nodes {
+		result := ""
+		if node.Result != nil {
+			raw, _ := json.Marshal(node.Result)
+			result = string(raw)
+		}
+		l.rows = append(l.rows, []string{node.Node, node.State, fmt.Sprintf("%.0f%%", node.Progress), node.Error})
+		l.wideRows = append(l.wideRows, []string{result})
+		l.names = append(l.names, node.Node)
+	}
+	printListing(l)
+}
+
+func operatorJobs(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	since, err := parseTimeBound(jobSince, time.Now())
+	if err != nil {
+		fmt.Printf("Error: --since: %v\n", err)
+		return
+	}
+	filter := client.JobFilter{
+		Operator: jobOperator,
+		State:    jobState,
+		Since:    since,
+		Limit:    jobLimit,
+	}
+
+	jobs, err := newClient(cluster).Jobs(cmd.Context(), filter)
+	if err != nil {
+		fmt.Printf("Error fetching jobs: %v\n", err)
+		return
+	}
+
+	l := &listing{
+		kind:       "jobs",
+		header:     []string{"Job ID", "Operator", "Operation", "State", "Nodes", "Age"},
+		wideHeader: []string{"Created At", "Error"},
+	}
+	// Filter again locally for servers that ignore the query parameters.
+	matched := []client.Job{}
+	for _, job := range jobs {
+		switch {
+		case filter.Operator != "" && job.Operator != filter.Operator,
+			filter.State != "" && !strings.EqualFold(job.State, filter.State),
+			!filter.Since.IsZero() && job.CreatedAt.Before(filter.Since):
+			continue
+		}
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+		matched = append(matched, job)
+
+		age := ""
+		if !job.CreatedAt.IsZero() {
+			age = humanize.Time(job.CreatedAt)
+		}
+		l.rows = append(l.rows, []string{job.ID, job.Operator, job.Operation, job.State, jobProgress(&job), age})
+		l.wideRows = append(l.wideRows, []string{job.CreatedAt.Format(time.RFC3339), job.Error})
+		l.names = append(l.names, job.ID)
+	}
+	l.data = matched
+	printListing(l)
+}
+
+func operatorCancel(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	job, err := newClient(cluster).CancelJob(cmd.Context(), args[0])
+	if err != nil {
+		fmt.Printf("Failed to cancel job: %v\n", err)
+		return
+	}
+	if job.State == "" {
+		fmt.Printf("Cancellation of job %s requested\n", job.ID)
+		return
+	}
+	fmt.Printf("Job %s is now %s\n", job.ID, job.State)
+}
+
+func operatorRetry(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	c := newClient(cluster)
+
+	job, err := c.Job(cmd.Context(), args[0])
+	if err != nil {
+		fmt.Printf("Error fetching job: %v\n", err)
+		return
+	}
+
+	payload, err := job.RetryPayload(retryFailedOnly)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	result, err := c.TriggerOperation(cmd.Context(), job.Operator, payload)
+	if err != nil {
+		fmt.Printf("Failed to retry job: %v\n", err)
+		return
+	}
+
+	targets := "all original targets"
+	if len(payload.TargetNodes) > 0 {
+		targets = strings.Join(payload.TargetNodes, ", ")
+	}
+	fmt.Printf("Retrying %s %s from job %s on %s\n", job.Operator, payload.Operation, job.ID, targets)
+	if result.JobID == "" {
+		return
+	}
+	fmt.Printf("Job ID: %v\n", result.JobID)
+	if triggerWait {
+		os.Exit(waitForJob(cmd.Context(), c, result.JobID))
+	}
+}
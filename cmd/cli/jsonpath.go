@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed kubectl-style JSONPath template such as
+// '{.nodes[*].address}' or '{range .nodes[*]}{.id}{"\t"}{.state}{"\n"}{end}'.
+//
+// Supported expressions are field access (.name, ['name']), recursive
+// descent (..name), wildcards (.* and [*]), indices ([0], [-1]), slices
+// ([1:3]), string literals and range/end blocks. Data must be the generic
+// form produced by encoding/json.
+type jsonPath struct {
+	nodes []jpNode
+}
+
+type jpNode interface{}
+
+type jpText struct{ text string }
+
+type jpPath struct{ path []jpSegment }
+
+type jpRange struct {
+	path []jpSegment
+	body []jpNode
+}
+
+type jpSegmentKind int
+
+const (
+	segRoot jpSegmentKind = iota
+	segField
+	segRecursive
+	segWildcard
+	segIndex
+	segSlice
+)
+
+type jpSegment struct {
+	kind       jpSegmentKind
+	name       string
+	index      int
+	start, end *int
+}
+
+func parseJSONPath(tmpl string) (*jsonPath, error) {
+	stack := [][]jpNode{{}}
+	var ranges []*jpRange
+
+	appendNode := func(n jpNode) {
+		stack[len(stack)-1] = append(stack[len(stack)-1], n)
+	}
+
+	for len(tmpl) > 0 {
+		open := strings.IndexByte(tmpl, '{')
+		if open < 0 {
+			appendNode(jpText{tmpl})
+			break
+		}
+		if open > 0 {
+			appendNode(jpText{tmpl[:open]})
+		}
+
+		closeIdx := closingIndex(tmpl, open, '}')
+		if closeIdx < 0 {
+			return nil, fmt.Errorf("jsonpath: unclosed '{' in %q", tmpl[open:])
+		}
+		expr := strings.TrimSpace(tmpl[open+1 : closeIdx])
+		tmpl = tmpl[closeIdx+1:]
+
+		switch {
+		case expr == "end":
+			if len(ranges) == 0 {
+				return nil, fmt.Errorf("jsonpath: {end} without {range}")
+			}
+			r := ranges[len(ranges)-1]
+			ranges = ranges[:len(ranges)-1]
+			r.body = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			appendNode(*r)
+		case strings.HasPrefix(expr, "range "):
+			path, err := parseJPExpr(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, &jpRange{path: path})
+			stack = append(stack, []jpNode{})
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			text, err := unquoteJP(expr)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid string literal %s: %v", expr, err)
+			}
+			appendNode(jpText{text})
+		default:
+			path, err := parseJPExpr(expr)
+			if err != nil {
+				return nil, err
+			}
+			appendNode(jpPath{path})
+		}
+	}
+
+	if len(ranges) > 0 {
+		return nil, fmt.Errorf("jsonpath: {range} without {end}")
+	}
+	return &jsonPath{nodes: stack[0]}, nil
+}
+
+// closingIndex returns the index of the closer ending the bracket at
+// open, ignoring closers inside quoted strings, or -1.
+func closingIndex(s string, open int, closer byte) int {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == closer:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteJP(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+func parseJPExpr(expr string) ([]jpSegment, error) {
+	var segs []jpSegment
+	rest := expr
+
+	switch {
+	case strings.HasPrefix(rest, "$"):
+		segs = append(segs, jpSegment{kind: segRoot})
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "@"):
+		rest = rest[1:]
+	}
+
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := readJPName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: expected field name after '..' in %q", expr)
+			}
+			segs = append(segs, jpSegment{kind: segRecursive, name: name})
+			rest = remaining
+		case strings.HasPrefix(rest, "."):
+			name, remaining := readJPName(rest[1:])
+			switch name {
+			case "":
+				// A lone '.' refers to the current object.
+			case "*":
+				segs = append(segs, jpSegment{kind: segWildcard})
+			default:
+				segs = append(segs, jpSegment{kind: segField, name: name})
+			}
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := closingIndex(rest, 0, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath: unclosed '[' in %q", expr)
+			}
+			seg, err := parseJPSubscript(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: %v in %q", err, expr)
+			}
+			segs = append(segs, seg)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath: unexpected %q in %q", rest, expr)
+		}
+	}
+	return segs, nil
+}
+
+func readJPName(s string) (string, string) {
+	i := 0
+	for i < len(s) && s[i] != '.' && s[i] != '[' {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func parseJPSubscript(sub string) (jpSegment, error) {
+	switch {
+	case sub == "*":
+		return jpSegment{kind: segWildcard}, nil
+	case strings.HasPrefix(sub, "'") || strings.HasPrefix(sub, `"`):
+		name, err := unquoteJP(sub)
+		if err != nil {
+			return jpSegment{}, err
+		}
+		return jpSegment{kind: segField, name: name}, nil
+	case strings.Contains(sub, ":"):
+		parts := strings.SplitN(sub, ":", 2)
+		seg := jpSegment{kind: segSlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return jpSegment{}, fmt.Errorf("invalid slice bound %q", part)
+			}
+			if i == 0 {
+				seg.start = &n
+			} else {
+				seg.end = &n
+			}
+		}
+		return seg, nil
+	default:
+		n, err := strconv.Atoi(sub)
+		if err != nil {
+			return jpSegment{}, fmt.Errorf("unsupported subscript [%s]", sub)
+		}
+		return jpSegment{kind: segIndex, index: n}, nil
+	}
+}
+
+// execute renders the template against data.
+func (p *jsonPath) execute(w io.Writer, data interface{}) error {
+	return executeJP(w, p.nodes, data, data)
+}
+
+func executeJP(w io.Writer, nodes []jpNode, root, current interface{}) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case jpText:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		case jpPath:
+			values := evalJP(n.path, root, current)
+			parts := make([]string, 0, len(values))
+			for _, v := range values {
+				parts = append(parts, formatJPValue(v))
+			}
+			if _, err := io.WriteString(w, strings.Join(parts, " ")); err != nil {
+				return err
+			}
+		case jpRange:
+			for _, v := range evalJP(n.path, root, current) {
+				if err := executeJP(w, n.body, root, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func evalJP(path []jpSegment, root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	for _, seg := range path {
+		var next []interface{}
+		for _, v := range values {
+			switch seg.kind {
+			case segRoot:
+				next = append(next, root)
+			case segField:
+				if m, ok := v.(map[string]interface{}); ok {
+					if child, ok := m[seg.name]; ok {
+						next = append(next, child)
+					}
+				}
+			case segRecursive:
+				next = append(next, collectJPField(v, seg.name)...)
+			case segWildcard:
+				next = append(next, jpChildren(v)...)
+			case segIndex:
+				if list, ok := v.([]interface{}); ok {
+					i := seg.index
+					if i < 0 {
+						i += len(list)
+					}
+					if i >= 0 && i < len(list) {
+						next = append(next, list[i])
+					}
+				}
+			case segSlice:
+				if list, ok := v.([]interface{}); ok {
+					start, end := 0, len(list)
+					if seg.start != nil {
+						start = clampJPIndex(*seg.start, len(list))
+					}
+					if seg.end != nil {
+						end = clampJPIndex(*seg.end, len(list))
+					}
+					if start < end {
+						next = append(next, list[start:end]...)
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func clampJPIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// jpChildren returns the elements of a list or the values of a map in key order.
+func jpChildren(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		children := make([]interface{}, 0, len(t))
+		for _, k := range keys {
+			children = append(children, t[k])
+		}
+		return children
+	}
+	return nil
+}
+
+func collectJPField(v interface{}, name string) []interface{} {
+	var found []interface{}
+	if m, ok := v.(map[string]interface{}); ok {
+		if child, ok := m[name]; ok {
+			found = append(found, child)
+		}
+	}
+	for _, child := range jpChildren(v) {
+		found = append(found, collectJPField(child, name)...)
+	}
+	return found
+}
+
+func formatJPValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		raw, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(raw)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const jsonPathTestData = `{
+	"cluster": "prod",
+	"nodes": [
+		{"id": "node001", "state": "alive", "port": 7946, "tags": {"zone": "a"}},
+		{"id": "node002", "state": "dead", "port": 7947, "tags": {"zone": "b"}},
+		{"id": "node003", "state": "alive", "port": 7948, "tags": {"zone": "c"}}
+	],
+	"labels": {"env": "prod", "a]b": "bracket", "team": "db"},
+	"leader": {"id": "node001", "healthy": true, "load": 0.5, "none": null}
+}`
+
+func TestJSONPath(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonPathTestData), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{name: "field", tmpl: "{.cluster}", want: "prod"},
+		{name: "root", tmpl: "{$.leader.id}", want: "node001"},
+		{name: "nested field", tmpl: "{.leader.healthy} {.leader.load}", want: "true 0.5"},
+		{name: "null", tmpl: "[{.leader.none}]", want: "[]"},
+		{name: "missing field", tmpl: "[{.nope.deeper}]", want: "[]"},
+		{name: "object", tmpl: "{.nodes[0].tags}", want: `{"zone":"a"}`},
+		{name: "list wildcard", tmpl: "{.nodes[*].id}", want: "node001 node002 node003"},
+		{name: "map wildcard in key order", tmpl: "{.labels.*}", want: "bracket prod db"},
+		{name: "index", tmpl: "{.nodes[1].id}", want: "node002"},
+		{name: "negative index", tmpl: "{.nodes[-1].id}", want: "node003"},
+		{name: "index out of range", tmpl: "[{.nodes[5].id}]", want: "[]"},
+		{name: "slice", tmpl: "{.nodes[0:2].id}", want: "node001 node002"},
+		{name: "open slice", tmpl: "{.nodes[1:].id}", want: "node002 node003"},
+		{name: "negative slice", tmpl: "{.nodes[-2:].id}", want: "node002 node003"},
+		{name: "slice past the end", tmpl: "{.nodes[:10].port}", want: "7946 7947 7948"},
+		{name: "recursive descent", tmpl: "{..zone}", want: "a b c"},
+		{name: "recursive descent through maps", tmpl: "{..id}", want: "node001 node001 node002 node003"},
+		{name: "quoted key", tmpl: "{.labels['env']}", want: "prod"},
+		{name: "double quoted key", tmpl: `{.labels["team"]}`, want: "db"},
+		{name: "quoted key with bracket", tmpl: "{.labels['a]b']}", want: "bracket"},
+		{name: "quoted key with brace", tmpl: `{.labels['}']}x`, want: "x"},
+		{name: "string literals", tmpl: `{.cluster}{"\t"}{'-'}{"\n"}`, want: "prod\t-\n"},
+		{name: "plain text", tmpl: "cluster={.cluster};", want: "cluster=prod;"},
+		{
+			name: "range",
+			tmpl: `{range .nodes[*]}{.id}{"\t"}{.state}{"\n"}{end}`,
+			want: "node001\talive\nnode002\tdead\nnode003\talive\n",
+		},
+		{
+			name: "range with root reference",
+			tmpl: `{range .nodes[1:]}{@.id}@{$.cluster} {end}`,
+			want: "node002@prod node003@prod ",
+		},
+		{
+			name: "nested range",
+			tmpl: `{range .nodes[0:2]}{.id}:{range .tags.*}{.}{end};{end}`,
+			want: "node001:a;node002:b;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseJSONPath(tt.tmpl)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.tmpl, err)
+			}
+			var buf bytes.Buffer
+			if err := p.execute(&buf, data); err != nil {
+				t.Fatalf("execute %q: %v", tt.tmpl, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{name: "unclosed brace", tmpl: "{.cluster", wantErr: "unclosed '{'"},
+		{name: "unclosed brace after quote", tmpl: "{.labels['}']", wantErr: "unclosed '{'"},
+		{name: "end without range", tmpl: "{.cluster}{end}", wantErr: "{end} without {range}"},
+		{name: "range without end", tmpl: "{range .nodes[*]}{.id}", wantErr: "{range} without {end}"},
+		{name: "unclosed bracket", tmpl: "{.nodes[0}", wantErr: "unclosed '['"},
+		{name: "descent without name", tmpl: "{..}", wantErr: "expected field name after '..'"},
+		{name: "bad subscript", tmpl: "{.nodes[x]}", wantErr: "unsupported subscript [x]"},
+		{name: "bad slice bound", tmpl: "{.nodes[a:1]}", wantErr: `invalid slice bound "a"`},
+		{name: "unterminated quoted key", tmpl: "{.labels['env]}", wantErr: "unclosed '{'"},
+		{name: "bad string literal", tmpl: `{"\q"}`, wantErr: "invalid string literal"},
+		{name: "unexpected text", tmpl: "{cluster}", wantErr: `unexpected "cluster"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSONPath(tt.tmpl)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parse %q: error = %v, want %q", tt.tmpl, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,792 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Flags of the cli-config commands
+var (
+	clusterNodes    map[string]string
+	clusterPort     int
+	clusterStrategy string
+)
+
+// configFile is the local config loaded as a YAML document, so that edits
+// keep the comments and key order of the file.
+type configFile struct {
+	path     string
+	root     *yaml.Node
+	original []byte
+	unlock   func()
+}
+
+// loadConfigFile locks and reads the config file viper loaded. The lock is
+// held until close, so concurrent invocations cannot lose each other's
+// edits.
+func loadConfigFile(ctx context.Context) (*configFile, error) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil, errors.New("no config file loaded")
+	}
+	unlock, err := lockConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		unlock()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	return &configFile{path: path, root: doc.Content[0], original: data, unlock: unlock}, nil
+}
+
+// close releases the lock on the file.
+func (f *configFile) close() {
+	f.unlock()
+}
+
+// save validates the edited config and atomically replaces the file. Only
+// problems the edit introduced are reported, so that a file with unrelated
+// issues can still be changed.
+func (f *configFile) save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f.root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return f.write(buf.Bytes())
+}
+
+// write validates data as the new content of the file and atomically
+// replaces the file with it.
+func (f *configFile) write(data []byte) error {
+	if err := checkIntroduced(f.original, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+// lockPollInterval is how often lockConfig retries a held lock.
+const lockPollInterval = 100 * time.Millisecond
+
+// lockConfig takes an exclusive lock guarding writes to the config file,
+// waiting until ctx is done for other invocations to release it. It locks
+// a file in the state directory, as the config itself is replaced on every
+// write and its directory is usually $HOME.
+func lockConfig(ctx context.Context) (func(), error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, "config.lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	for waited := false; ; waited = true {
+		locked, err := tryLockFile(lock)
+		if err != nil {
+			lock.Close()
+			return nil, fmt.Errorf("locking %s: %w", lock.Name(), err)
+		}
+		if locked {
+			break
+		}
+		if !waited {
+			fmt.Fprintf(os.Stderr, "Waiting for another gocluster command to release %s...\n", lock.Name())
+		}
+		// Polling rather than a blocking lock lets Ctrl-C stop the wait.
+		select {
+		case <-ctx.Done():
+			lock.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+	return func() {
+		unlockFile(lock)
+		lock.Close()
+	}, nil
+}
+
+// stateDir returns the directory gocluster keeps its state in,
+// $XDG_STATE_HOME/gocluster or ~/.local/state/gocluster.
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gocluster"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "gocluster"), nil
+}
+
+// errNotPatchable is returned by patchScalar for layouts it cannot edit in
+// place, such as flow mappings and block scalars.
+var errNotPatchable = errors.New("value cannot be edited in place")
+
+// patchScalar returns data with the scalar at the key path set to value.
+// Only the bytes of the old value are replaced, keeping its quoting; a
+// missing key is inserted as the first key of its mapping, or appended to
+// the file at the top level. Everything else stays exactly as written.
+func patchScalar(data []byte, value string, keys ...string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, errNotPatchable
+	}
+	root := doc.Content[0]
+
+	var keyNode *yaml.Node
+	node := root
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+			return nil, errNotPatchable
+		}
+		parent := node
+		if keyNode, node = mappingEntry(parent, key); keyNode == nil {
+			return insertKeys(data, root, parent, keys[i:], value)
+		}
+	}
+	if node.Kind != yaml.ScalarNode || node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, errNotPatchable
+	}
+
+	if node.Tag == "!!null" && node.Value == "" {
+		// "key:" without a value; the value goes after the colon.
+		start := offsetOf(data, keyNode.Line, keyNode.Column)
+		colon := bytes.IndexByte(data[start:], ':')
+		if colon < 0 {
+			return nil, errNotPatchable
+		}
+		at := start + colon + 1
+		return splice(data, at, at, " "+formatScalar(value, 0)), nil
+	}
+	start := offsetOf(data, node.Line, node.Column)
+	end := plainEnd(data, start)
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		end = quotedEnd(data, start, '"')
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		end = quotedEnd(data, start, '\'')
+	}
+	if end < 0 {
+		return nil, errNotPatchable
+	}
+	return splice(data, start, end, formatScalar(value, node.Style)), nil
+}
+
+// insertKeys adds the key path with value to the block mapping parent:
+// as its first key, or at the end of the file for the top level.
+func insertKeys(data []byte, root, parent *yaml.Node, keys []string, value string) ([]byte, error) {
+	unit := indentUnit(root)
+	var text strings.Builder
+	writeKeys := func(indent int) {
+		for i, key := range keys {
+			text.WriteString(strings.Repeat(" ", indent+i*unit) + key + ":")
+			if i == len(keys)-1 {
+				text.WriteString(" " + formatScalar(value, 0))
+			}
+			text.WriteString("\n")
+		}
+	}
+
+	if parent == root {
+		writeKeys(0)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		return append(data, text.String()...), nil
+	}
+	if len(parent.Content) == 0 {
+		return nil, errNotPatchable
+	}
+	first := parent.Content[0]
+	writeKeys(first.Column - 1)
+	at := offsetOf(data, first.Line, 1)
+	return splice(data, at, at, text.String()), nil
+}
+
+// indentUnit returns the indentation of nested mappings in the file,
+// defaulting to two spaces.
+func indentUnit(root *yaml.Node) int {
+	for i := 1; i < len(root.Content); i += 2 {
+		value := root.Content[i]
+		if value.Kind == yaml.MappingNode && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+			if unit := value.Content[0].Column - root.Content[i-1].Column; unit > 0 {
+				return unit
+			}
+		}
+	}
+	return 2
+}
+
+// mappingEntry returns the key and value nodes of key, matched
+// case-insensitively like mappingValue, or nils.
+func mappingEntry(node *yaml.Node, key string) (keyNode, valueNode *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			keyNode, valueNode = node.Content[i], node.Content[i+1]
+		}
+	}
+	return keyNode, valueNode
+}
+
+// offsetOf converts a 1-based line and column, counted in characters as
+// the YAML parser does, to a byte offset in data.
+func offsetOf(data []byte, line, column int) int {
+	offset := 0
+	for ; line > 1 && offset < len(data); line-- {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			return len(data)
+		}
+		offset += next + 1
+	}
+	for ; column > 1 && offset < len(data); column-- {
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// quotedEnd returns the offset after the quoted scalar starting at start,
+// or -1.
+func quotedEnd(data []byte, start int, quote byte) int {
+	for i := start + 1; i < len(data); i++ {
+		switch {
+		case quote == '"' && data[i] == '\\':
+			i++
+		case data[i] == quote && quote == '\'' && i+1 < len(data) && data[i+1] == '\'':
+			i++
+		case data[i] == quote:
+			return i + 1
+		case data[i] == '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// plainEnd returns the offset after the plain scalar starting at start,
+// which ends before a comment or the end of the line.
+func plainEnd(data []byte, start int) int {
+	end := bytes.IndexByte(data[start:], '\n')
+	if end < 0 {
+		end = len(data) - start
+	}
+	line := data[start : start+end]
+	if comment := bytes.Index(line, []byte(" #")); comment >= 0 {
+		line = line[:comment]
+	}
+	return start + len(bytes.TrimRight(line, " \t\r"))
+}
+
+// formatScalar renders value for the file in the given style, quoting
+// plain values that would otherwise not read back as the string.
+func formatScalar(value string, style yaml.Style) string {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+func splice(data []byte, start, end int, text string) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(text))
+	out = append(out, data[:start]...)
+	out = append(out, text...)
+	return append(out, data[end:]...)
+}
+
+// cluster returns the mapping of the named cluster, or nil.
+func (f *configFile) cluster(name string) *yaml.Node {
+	return mappingValue(f.root, "clusters", name)
+}
+
+// defaultClusterKey returns the key path holding the selected cluster:
+// selected_cluster in files that still use it, cli.default_cluster
+// otherwise.
+func (f *configFile) defaultClusterKey() []string {
+	if mappingValue(f.root, "selected_cluster") != nil && mappingValue(f.root, "cli", "default_cluster") == nil {
+		return []string{"selected_cluster"}
+	}
+	return []string{"cli", "default_cluster"}
+}
+
+func (f *configFile) defaultCluster() string {
+	if node := mappingValue(f.root, f.defaultClusterKey()...); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+func (f *configFile) setDefaultCluster(name string) {
+	keys := f.defaultClusterKey()
+	parent := f.root
+	for _, key := range keys[:len(keys)-1] {
+		parent = ensureMapping(parent, key)
+	}
+	setMappingValue(parent, keys[len(keys)-1], stringNode(name))
+}
+
+// selectCluster makes name the default cluster and clears the current
+// context, which would keep overriding it, returning the context that was
+// current. Only the bytes of the two values change, so the file keeps its
+// comments and layout; files patchScalar cannot edit are re-serialized.
+func (f *configFile) selectCluster(name string) (string, error) {
+	previousContext := ""
+	if node := mappingValue(f.root, "cli", "current_context"); node != nil {
+		previousContext = node.Value
+	}
+	data, err := patchScalar(f.original, name, f.defaultClusterKey()...)
+	if err == nil && previousContext != "" {
+		data, err = patchScalar(data, "", "cli", "current_context")
+	}
+	if errors.Is(err, errNotPatchable) {
+		f.setDefaultCluster(name)
+		if previousContext != "" {
+			setMappingValue(ensureMapping(f.root, "cli"), "current_context", stringNode(""))
+		}
+		return previousContext, f.save()
+	}
+	if err != nil {
+		return "", err
+	}
+	return previousContext, f.write(data)
+}
+
+// checkIntroduced fails with the problems 'config validate' reports for
+// data that it does not report for original, so a config that already has
+// problems can still be changed.
+func checkIntroduced(original, data []byte) error {
+	existing := map[string]bool{}
+	if problems, err := checkConfig(original); err == nil {
+		for _, p := range problems {
+			existing[p.message] = true
+		}
+	}
+	problems, err := checkConfig(data)
+	if err != nil {
+		return err
+	}
+	var introduced []string
+	for _, p := range problems {
+		if !existing[p.message] {
+			introduced = append(introduced, fmt.Sprintf("line %d: %s", p.line, p.message))
+		}
+	}
+	if len(introduced) > 0 {
+		return fmt.Errorf("the resulting config is invalid:\n  %s", strings.Join(introduced, "\n  "))
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so readers never see a partial file. An existing file
+// keeps its permissions, and a symlink, e.g. from a dotfile manager, is
+// followed so the link stays in place.
+func writeFileAtomic(path string, data []byte) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	perm := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// ensureMapping returns the mapping stored under key, adding it if needed.
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(node, key, value)
+	return value
+}
+
+// setMappingValue replaces the value of key, or appends the key. A scalar
+// replacing a scalar keeps its comments and quoting.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !strings.EqualFold(node.Content[i].Value, key) {
+			continue
+		}
+		if old := node.Content[i+1]; old.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode {
+			old.Value, old.Tag = value.Value, value.Tag
+		} else {
+			node.Content[i+1] = value
+		}
+		return
+	}
+	node.Content = append(node.Content, stringNode(key), value)
+}
+
+// deleteMappingKey removes key and its value, reporting whether it existed.
+func deleteMappingKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func stringNode(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+func addressNode(addr string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: addr, Style: yaml.DoubleQuotedStyle}
+}
+
+func viewCLIConfig(cmd *cobra.Command, args []string) {
+	path := viper.ConfigFileUsed()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("# %s\n%s", path, data)
+}
+
+func addCluster(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	if f.cluster(name) != nil {
+		fmt.Printf("Error: cluster '%s' already exists\n", name)
+		return
+	}
+
+	nodes := &yaml.Node{Kind: yaml.MappingNode}
+	for _, id := range sortedKeys(clusterNodes) {
+		nodes.Content = append(nodes.Content, stringNode(id), addressNode(clusterNodes[id]))
+	}
+	cluster := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(cluster, "name", stringNode(name))
+	setMappingValue(cluster, "nodes", nodes)
+	if clusterPort != 0 {
+		setMappingValue(cluster, "port", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(clusterPort)})
+	}
+	if clusterStrategy != "" {
+		setMappingValue(cluster, "strategy", stringNode(clusterStrategy))
+	}
+	setMappingValue(ensureMapping(f.root, "clusters"), name, cluster)
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Cluster '%s' added with %d node(s)\n", name, len(clusterNodes))
+}
+
+func removeCluster(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	if !deleteMappingKey(ensureMapping(f.root, "clusters"), name) {
+		fmt.Printf("Error: cluster '%s' not found\n", name)
+		return
+	}
+	wasDefault := strings.EqualFold(f.defaultCluster(), name)
+	if wasDefault {
+		f.setDefaultCluster("")
+	}
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Cluster '%s' removed\n", name)
+	if wasDefault {
+		fmt.Println("It was the default cluster; select another with 'gocluster use <cluster_name>'.")
+	}
+}
+
+func addNode(cmd *cobra.Command, args []string) {
+	name, id, addr := args[0], args[1], args[2]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	cluster := f.cluster(name)
+	if cluster == nil {
+		fmt.Printf("Error: cluster '%s' not found\n", name)
+		return
+	}
+	nodes := ensureMapping(cluster, "nodes")
+	if mappingValue(nodes, id) != nil {
+		fmt.Printf("Error: node '%s' already exists in cluster '%s'\n", id, name)
+		return
+	}
+	setMappingValue(nodes, id, addressNode(addr))
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Node '%s' (%s) added to cluster '%s'\n", id, addr, name)
+}
+
+func removeNode(cmd *cobra.Command, args []string) {
+	name, id := args[0], args[1]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	cluster := f.cluster(name)
+	if cluster == nil {
+		fmt.Printf("Error: cluster '%s' not found\n", name)
+		return
+	}
+	if !deleteMappingKey(ensureMapping(cluster, "nodes"), id) {
+		fmt.Printf("Error: node '%s' not found in cluster '%s'\n", id, name)
+		return
+	}
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Node '%s' removed from cluster '%s'\n", id, name)
+}
+
+func renameCluster(cmd *cobra.Command, args []string) {
+	oldName, newName := args[0], args[1]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	clusters := ensureMapping(f.root, "clusters")
+	if f.cluster(newName) != nil {
+		fmt.Printf("Error: cluster '%s' already exists\n", newName)
+		return
+	}
+	renamed := false
+	for i := 0; i+1 < len(clusters.Content); i += 2 {
+		if strings.EqualFold(clusters.Content[i].Value, oldName) {
+			clusters.Content[i].Value = newName
+			if node := mappingValue(clusters.Content[i+1], "name"); node != nil && strings.EqualFold(node.Value, oldName) {
+				node.Value = newName
+			}
+			renamed = true
+		}
+	}
+	if !renamed {
+		fmt.Printf("Error: cluster '%s' not found\n", oldName)
+		return
+	}
+	if strings.EqualFold(f.defaultCluster(), oldName) {
+		f.setDefaultCluster(newName)
+	}
+	if contexts := mappingValue(f.root, "contexts"); contexts != nil && contexts.Kind == yaml.MappingNode {
+		for i := 1; i < len(contexts.Content); i += 2 {
+			if node := mappingValue(contexts.Content[i], "cluster"); node != nil && strings.EqualFold(node.Value, oldName) {
+				node.Value = newName
+			}
+		}
+	}
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Cluster '%s' renamed to '%s'\n", oldName, newName)
+}
+
+func setDefaultCluster(cmd *cobra.Command, args []string) {
+	name := args[0]
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	if f.cluster(name) == nil {
+		fmt.Printf("Error: cluster '%s' not found\n", name)
+		return
+	}
+	f.setDefaultCluster(name)
+
+	if err := f.save(); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	fmt.Printf("Default cluster set to '%s'\n", name)
+}
+
+// editCLIConfig opens a copy of the config in $VISUAL or $EDITOR and
+// replaces the file once the copy validates. Invalid edits can be
+// reopened or discarded.
+func editCLIConfig(cmd *cobra.Command, args []string) {
+	path := viper.ConfigFileUsed()
+	original, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	tmp, err := os.CreateTemp("", "gocluster-*.yaml")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// The edit is kept in the temporary file if it cannot be saved.
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			fmt.Printf("Error running editor: %v\n", err)
+			return
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes")
+			return
+		}
+		if err := checkIntroduced(original, edited); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			if confirm(cmd.Context(), "Edit again?") {
+				continue
+			}
+			fmt.Println("Changes discarded")
+			return
+		}
+		if err := replaceEditedConfig(cmd.Context(), path, original, edited); err != nil {
+			keep = true
+			fmt.Printf("Error saving config: %v\n", err)
+			fmt.Printf("Your edit is kept in %s\n", tmp.Name())
+			return
+		}
+		fmt.Printf("%s updated\n", path)
+		return
+	}
+}
+
+// replaceEditedConfig writes the edited config unless the file changed
+// while it was being edited.
+func replaceEditedConfig(ctx context.Context, path string, original, edited []byte) error {
+	unlock, err := lockConfig(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, original) {
+		return fmt.Errorf("%s was changed by another command while editing", path)
+	}
+	return writeFileAtomic(path, edited)
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may carry arguments, e.g. "code --wait".
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPatchScalar(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		keys    []string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "plain value keeps comment, blank lines and indentation",
+			data:  "version: 1\n\ncli:\n    default_cluster: stg   # staging\n\n    timeout: 5\n",
+			keys:  []string{"cli", "default_cluster"},
+			value: "prod",
+			want:  "version: 1\n\ncli:\n    default_cluster: prod   # staging\n\n    timeout: 5\n",
+		},
+		{
+			name:  "double quoted",
+			data:  "selected_cluster: \"stg\" # old\n",
+			keys:  []string{"selected_cluster"},
+			value: "prod \"eu\"",
+			want:  "selected_cluster: \"prod \\\"eu\\\"\" # old\n",
+		},
+		{
+			name:  "single quoted",
+			data:  "selected_cluster: 'stg'\n",
+			keys:  []string{"selected_cluster"},
+			value: "it's",
+			want:  "selected_cluster: 'it''s'\n",
+		},
+		{
+			name:  "plain value that needs quoting",
+			data:  "cli:\n  current_context: prod\n",
+			keys:  []string{"cli", "current_context"},
+			value: "",
+			want:  "cli:\n  current_context: \"\"\n",
+		},
+		{
+			name:  "keys match case-insensitively",
+			data:  "CLI:\n  Default_Cluster: stg\n",
+			keys:  []string{"cli", "default_cluster"},
+			value: "prod",
+			want:  "CLI:\n  Default_Cluster: prod\n",
+		},
+		{
+			name:  "empty value",
+			data:  "cli:\n  default_cluster:\n  timeout: 5\n",
+			keys:  []string{"cli", "default_cluster"},
+			value: "prod",
+			want:  "cli:\n  default_cluster: prod\n  timeout: 5\n",
+		},
+		{
+			name:  "missing key in a mapping",
+			data:  "# settings\ncli:\n    # per request\n    timeout: 5\n",
+			keys:  []string{"cli", "default_cluster"},
+			value: "prod",
+			want:  "# settings\ncli:\n    # per request\n    default_cluster: prod\n    timeout: 5\n",
+		},
+		{
+			name:  "missing mapping",
+			data:  "clusters:\n    prod:\n        port: 7946",
+			keys:  []string{"cli", "default_cluster"},
+			value: "prod",
+			want:  "clusters:\n    prod:\n        port: 7946\ncli:\n    default_cluster: prod\n",
+		},
+		{
+			name:  "multi-byte characters before the value",
+			data:  "# ünïcode\nnötë: ü\n",
+			keys:  []string{"nötë"},
+			value: "x",
+			want:  "# ünïcode\nnötë: x\n",
+		},
+		{
+			name:    "flow mapping",
+			data:    "cli: {default_cluster: stg}\n",
+			keys:    []string{"cli", "default_cluster"},
+			value:   "prod",
+			wantErr: errNotPatchable,
+		},
+		{
+			name:    "block scalar",
+			data:    "selected_cluster: |\n  stg\n",
+			keys:    []string{"selected_cluster"},
+			value:   "prod",
+			wantErr: errNotPatchable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchScalar([]byte(tt.data), tt.value, tt.keys...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomicFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "gocluster.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("old\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, ".gocluster.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("%s is no longer a symlink (%v)", link, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("target = %q, want %q", data, "new\n")
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o640 {
+		t.Errorf("target mode = %v, want %v", perm, os.FileMode(0o640))
+	}
+}
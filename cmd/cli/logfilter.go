@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels in increasing severity; levelUnknown lines inherit the level of
+// the previous line from the same node, so stack traces stay with their
+// error line.
+const (
+	levelUnknown = iota
+	levelTrace
+	levelDebug
+	levelInfo
+	levelWarn
+	levelError
+	levelFatal
+)
+
+var levelNames = map[string]int{
+	"trace":    levelTrace,
+	"debug":    levelDebug,
+	"dbg":      levelDebug,
+	"info":     levelInfo,
+	"inf":      levelInfo,
+	"notice":   levelInfo,
+	"warn":     levelWarn,
+	"warning":  levelWarn,
+	"wrn":      levelWarn,
+	"error":    levelError,
+	"err":      levelError,
+	"erro":     levelError,
+	"fatal":    levelFatal,
+	"panic":    levelFatal,
+	"critical": levelFatal,
+	"crit":     levelFatal,
+}
+
+// logFilter applies the --grep, --exclude, --since, --until and --level
+// flags locally, for servers that ignore the equivalent query parameters.
+type logFilter struct {
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	since    time.Time
+	until    time.Time
+	minLevel int
+
+	mu        sync.Mutex
+	lastLevel map[string]int
+}
+
+func newLogFilter(grep, exclude []string, since, until time.Time, level string) (*logFilter, error) {
+	f := &logFilter{since: since, until: until, lastLevel: map[string]int{}}
+
+	for _, pattern := range grep {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep pattern %q: %v", pattern, err)
+		}
+		f.include = append(f.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude pattern %q: %v", pattern, err)
+		}
+		f.exclude = append(f.exclude, re)
+	}
+
+	if level != "" {
+		lvl, ok := levelNames[strings.ToLower(level)]
+		if !ok {
+			return nil, fmt.Errorf("unknown log level %q (valid levels: trace, debug, info, warn, error, fatal)", level)
+		}
+		f.minLevel = lvl
+	}
+	return f, nil
+}
+
+// match reports whether line passes the filter. Lines of a node must be
+// passed in order for level inheritance to work.
+func (f *logFilter) match(line logLine) bool {
+	if f.minLevel != levelUnknown {
+		f.mu.Lock()
+		lvl := parseLineLevel(line.text)
+		if lvl == levelUnknown {
+			lvl = f.lastLevel[line.node]
+		} else {
+			f.lastLevel[line.node] = lvl
+		}
+		f.mu.Unlock()
+		if lvl < f.minLevel {
+			return false
+		}
+	}
+
+	if !line.ts.IsZero() {
+		if !f.since.IsZero() && line.ts.Before(f.since) {
+			return false
+		}
+		if !f.until.IsZero() && line.ts.After(f.until) {
+			return false
+		}
+	}
+
+	for _, re := range f.exclude {
+		if re.MatchString(line.text) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(line.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeBound parses a --since/--until value: a duration relative to
+// now such as 15m, or an absolute RFC3339 timestamp or date.
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 15m or a timestamp like 2006-01-02T15:04:05Z", value)
+}
+
+// structuredFields decodes a JSON log line, or returns nil.
+func structuredFields(line string) map[string]interface{} {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// logfmtValue returns the value of key in a logfmt line such as
+// `time=... level=warn msg="..."`.
+func logfmtValue(line, key string) string {
+	for _, field := range strings.Fields(line) {
+		if k, v, ok := strings.Cut(field, "="); ok && k == key {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// parseLineLevel detects the level of a JSON, logfmt or plain text log line.
+func parseLineLevel(line string) int {
+	if fields := structuredFields(line); fields != nil {
+		for _, key := range []string{"level", "lvl", "severity"} {
+			if s, ok := fields[key].(string); ok {
+				return levelNames[strings.ToLower(s)]
+			}
+		}
+		return levelUnknown
+	}
+
+	for _, key := range []string{"level", "lvl"} {
+		if v := logfmtValue(line, key); v != "" {
+			return levelNames[strings.ToLower(v)]
+		}
+	}
+
+	// Plain text: look for a level word among the first few tokens.
+	fields := strings.Fields(line)
+	if len(fields) > 4 {
+		fields = fields[:4]
+	}
+	for _, field := range fields {
+		word := strings.Trim(field, "[]():")
+		// Only upper-case or bracketed words, so "error" in a message
+		// does not count.
+		if word != strings.ToUpper(word) && word == field {
+			continue
+		}
+		if lvl, ok := levelNames[strings.ToLower(word)]; ok {
+			return lvl
+		}
+	}
+	return levelUnknown
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// mergeWindow is how long followed lines are held back so lines from
+// different nodes can be printed in timestamp order.
+const mergeWindow = 250 * time.Millisecond
+
+// logLine is a log line tagged with the node it came from.
+type logLine struct {
+	node string
+	text string
+	ts   time.Time
+}
+
+func viewLogs(cmd *cobra.Command, args []string) {
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	c := newClient(cluster)
+
+	opts, filter, err := logQuery(time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	nodes, err := logTargets(cmd.Context(), c, cluster)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	printer := newLogPrinter(nodes)
+	if followLogs {
+		ctx := cmd.Context()
+		if !opts.Until.IsZero() {
+			// Nothing can match once --until has passed.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, opts.Until)
+			defer cancel()
+		}
+		followLogLines(ctx, c, nodes, opts, filter, printer)
+		return
+	}
+
+	perNode := make([][]logLine, len(nodes))
+	forEachNode(nodes, func(i int, node string) {
+		lines, err := c.Logs(cmd.Context(), node, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching logs from %s: %v\n", node, err)
+			return
+		}
+		for _, text := range lines {
+			line := newLogLine(node, text, time.Time{})
+			if filter.match(line) {
+				perNode[i] = append(perNode[i], line)
+			}
+		}
+	})
+
+	for _, line := range mergeLogLines(perNode) {
+		printer.print(line)
+	}
+}
+
+// logQuery builds the server-side query and the local filter from the
+// logs flags.
+func logQuery(now time.Time) (client.LogsOptions, *logFilter, error) {
+	since, err := parseTimeBound(logSince, now)
+	if err != nil {
+		return client.LogsOptions{}, nil, fmt.Errorf("--since: %w", err)
+	}
+	until, err := parseTimeBound(logUntil, now)
+	if err != nil {
+		return client.LogsOptions{}, nil, fmt.Errorf("--until: %w", err)
+	}
+
+	filter, err := newLogFilter(logGrep, logExclude, since, until, logLevel)
+	if err != nil {
+		return client.LogsOptions{}, nil, err
+	}
+
+	opts := client.LogsOptions{
+		Lines:   logLines,
+		Since:   since,
+		Until:   until,
+		Grep:    logGrep,
+		Exclude: logExclude,
+		Level:   logLevel,
+	}
+	return opts, filter, nil
+}
+
+// logTargets resolves the nodes to read logs from: --all, the global
+// --nodes flag, --node, or the current leader.
+func logTargets(ctx context.Context, c *client.Client, cluster *client.ClusterConfig) ([]string, error) {
+	switch {
+	case allLogs:
+		return sortedKeys(cluster.Nodes), nil
+	case len(targetNodes) > 0:
+		return targetNodes, nil
+	case logNode != "":
+		return []string{logNode}, nil
+	}
+
+	// Get leader node if no specific node is specified
+	leader, err := c.Leader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching leader: %w", err)
+	}
+	return []string{leader.ID}, nil
+}
+
+// followLogLines tails every node concurrently until interrupted.
+func followLogLines(ctx context.Context, c *client.Client, nodes []string, query client.LogsOptions, filter *logFilter, printer *logPrinter) {
+	lines := make(chan logLine, 256)
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			opts := client.FollowOptions{
+				LogsOptions:  query,
+				PollInterval: pollInterval,
+				OnReconnect: func(attempt int, delay time.Duration, err error) {
+					fmt.Fprintf(os.Stderr, "Lost log stream from %s (%v), reconnecting in %s (attempt %d)\n", node, err, delay, attempt)
+				},
+			}
+			err := c.FollowLogs(ctx, node, opts, func(entry client.LogEntry) error {
+				line := newLogLine(node, entry.Line, entry.Timestamp)
+				if !filter.match(line) {
+					return nil
+				}
+				select {
+				case lines <- line:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				fmt.Fprintf(os.Stderr, "Error streaming logs from %s: %v\n", node, err)
+			}
+		}(node)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	// A single node needs no reordering.
+	if len(nodes) == 1 {
+		for line := range lines {
+			printer.print(line)
+		}
+		return
+	}
+
+	type pending struct {
+		line    logLine
+		arrived time.Time
+	}
+	var buffered []pending
+	flush := func(cutoff time.Time) {
+		ready := buffered[:0:0]
+		keep := buffered[:0:0]
+		for _, p := range buffered {
+			if p.arrived.Before(cutoff) {
+				ready = append(ready, p)
+			} else {
+				keep = append(keep, p)
+			}
+		}
+		sort.SliceStable(ready, func(i, j int) bool {
+			return lineTime(ready[i].line, ready[i].arrived).Before(lineTime(ready[j].line, ready[j].arrived))
+		})
+		for _, p := range ready {
+			printer.print(p.line)
+		}
+		buffered = keep
+	}
+
+	ticker := time.NewTicker(mergeWindow / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush(time.Now().Add(time.Hour))
+				return
+			}
+			buffered = append(buffered, pending{line: line, arrived: time.Now()})
+		case now := <-ticker.C:
+			flush(now.Add(-mergeWindow))
+		}
+	}
+}
+
+func lineTime(line logLine, fallback time.Time) time.Time {
+	if line.ts.IsZero() {
+		return fallback
+	}
+	return line.ts
+}
+
+func newLogLine(node, text string, ts time.Time) logLine {
+	if ts.IsZero() {
+		ts, _ = parseLineTime(text)
+	}
+	return logLine{node: node, text: text, ts: ts}
+}
+
+// lineTimeLayouts are the timestamp formats recognised at the start of a
+// log line.
+var lineTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+}
+
+// parseLineTime extracts the timestamp of a log line: the time field of a
+// JSON or logfmt line, or a leading timestamp of a plain text line.
+func parseLineTime(line string) (time.Time, bool) {
+	var structured string
+	if fields := structuredFields(line); fields != nil {
+		for _, key := range []string{"time", "timestamp", "ts"} {
+			if s, ok := fields[key].(string); ok {
+				structured = s
+				break
+			}
+		}
+	} else {
+		structured = logfmtValue(line, "time")
+		if structured == "" {
+			structured = logfmtValue(line, "ts")
+		}
+	}
+	if structured != "" {
+		if t, err := time.Parse(time.RFC3339Nano, structured); err == nil {
+			return t, true
+		}
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	candidates := []string{fields[0]}
+	if len(fields) > 1 {
+		candidates = append(candidates, fields[0]+" "+fields[1])
+	}
+	for _, candidate := range candidates {
+		candidate = strings.Trim(candidate, "[]")
+		for _, layout := range lineTimeLayouts {
+			if t, err := time.Parse(layout, candidate); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// mergeLogLines merges per-node lines by timestamp. Lines without a
+// timestamp stay behind the preceding line of the same node.
+func mergeLogLines(perNode [][]logLine) []logLine {
+	var merged []logLine
+	heads := make([]int, len(perNode))
+	lastTS := make([]time.Time, len(perNode))
+
+	for {
+		best := -1
+		var bestTS time.Time
+		for i, lines := range perNode {
+			if heads[i] >= len(lines) {
+				continue
+			}
+			ts := lines[heads[i]].ts
+			if ts.IsZero() {
+				ts = lastTS[i]
+			}
+			if best < 0 || ts.Before(bestTS) {
+				best, bestTS = i, ts
+			}
+		}
+		if best < 0 {
+			return merged
+		}
+		line := perNode[best][heads[best]]
+		if !line.ts.IsZero() {
+			lastTS[best] = line.ts
+		}
+		merged = append(merged, line)
+		heads[best]++
+	}
+}
+
+// logPrinter writes log lines, prefixed with a colored node ID when more
+// than one node is being read.
+type logPrinter struct {
+	prefix bool
+	color  bool
+	width  int
+	mu     sync.Mutex
+}
+
+// nodeColors are the ANSI colors used for node prefixes.
+var nodeColors = []string{"31", "32", "33", "34", "35", "36", "91", "92", "93", "94", "95", "96"}
+
+func newLogPrinter(nodes []string) *logPrinter {
+	p := &logPrinter{
+		prefix: len(nodes) > 1,
+		color:  !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout),
+	}
+	for _, node := range nodes {
+		if len(node) > p.width {
+			p.width = len(node)
+		}
+	}
+	return p
+}
+
+func (p *logPrinter) print(line logLine) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.prefix {
+		fmt.Println(line.text)
+		return
+	}
+	prefix := fmt.Sprintf("%-*s |", p.width, line.node)
+	if p.color {
+		h := fnv.New32a()
+		h.Write([]byte(line.node))
+		prefix = fmt.Sprintf("\033[%sm%s\033[0m", nodeColors[h.Sum32()%uint32(len(nodeColors))], prefix)
+	}
+	fmt.Println(prefix, line.text)
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
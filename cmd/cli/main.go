@@ -1,78 +1,92 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
 	humanize "github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-type Config struct {
-	Clusters        map[string]ClusterConfig `mapstructure:"clusters"`
-	SelectedCluster string                   `mapstructure:"selected_cluster"`
-	Timeout         int                      `mapstructure:"timeout"`
-	Retries         int                      `mapstructure:"retries"`
-}
-
-type ClusterConfig struct {
-	Name  string            `mapstructure:"name"`
-	Nodes map[string]string `mapstructure:"nodes"`
-	Port  int               `mapstructure:"port"`
-}
-
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data"`
-	Error   string      `json:"error"`
-}
-
-type OperatorSchema struct {
-	Name        string                     `json:"name"`
-	Version     string                     `json:"version"`
-	Description string                     `json:"description"`
-	Operations  map[string]OperationSchema `json:"operations"`
-}
-
-type OperationSchema struct {
-	Description string                 `json:"description"`
-	Parameters  map[string]ParamSchema `json:"parameters"`
-	Config      map[string]ParamSchema `json:"config"`
-}
-
-type ParamSchema struct {
-	Type        string      `json:"type"`
-	Required    bool        `json:"required"`
-	Default     interface{} `json:"default"`
-	Description string      `json:"description"`
-}
-
-type OperatorPayload struct {
-	Operation   string                 `json:"operation"`
-	Config      map[string]interface{} `json:"config,omitempty"`
-	Params      map[string]interface{} `json:"params,omitempty"`
-	Parallel    bool                   `json:"parallel"`
-	TargetNodes []string               `json:"target_nodes,omitempty"`
-}
-
 // Global flags
 var (
-	parallel    bool
-	targetNodes []string
-	logNode     string
-	logLines    int
-	followLogs  bool
-	config      Config
-	rootCmd     = &cobra.Command{Use: "gocluster"}
+	parallel            bool
+	targetNodes         []string
+	logNode             string
+	logLines            int
+	followLogs          bool
+	allLogs             bool
+	noColor             bool
+	logGrep             []string
+	logExclude          []string
+	logSince            string
+	logUntil            string
+	logLevel            string
+	pollInterval        time.Duration
+	outputFormat        string
+	watchMode           bool
+	watchInterval       time.Duration
+	staleAfter          time.Duration
+	backupYes           bool
+	backupNoWait        bool
+	triggerWait         bool
+	triggerDryRun       bool
+	triggerShowResolved bool
+	triggerParamsFile   string
+	triggerConfigFile   string
+	jobTimeout          time.Duration
+	jobInterval         time.Duration
+	jobOperator         string
+	jobState            string
+	jobSince            string
+	jobLimit            int
+	retryFailedOnly     bool
+	schemaRefresh       bool
+	schemaOffline       bool
+	viaNode             string
+	verbose             bool
+	config              Config
+	rootCmd             = &cobra.Command{Use: "gocluster"}
+	validateConfigCmd   = &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check the local .gocluster.yaml for unknown keys and type errors",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   validateConfig,
+	}
+	// The cli-config and context commands ignore --cluster and
+	// GOCLUSTER_CLUSTER, so they can still repair a config the override
+	// does not match.
+	cliConfigCmd = &cobra.Command{
+		Use:   "cli-config",
+		Short: "Manage the local .gocluster.yaml",
+		Long: `Manage the local .gocluster.yaml.
+
+Unlike 'config', which changes the configuration of the cluster itself,
+these commands edit the file the CLI was loaded from. Every change is
+validated and written atomically.`,
+	}
+	contextCmd = &cobra.Command{
+		Use:   "context",
+		Short: "Manage contexts bundling a cluster with its defaults and credentials",
+	}
+
+	// overrides holds the per-invocation settings from --cluster and the
+	// GOCLUSTER_CLUSTER and GOCLUSTER_CONFIG environment variables. It is
+	// kept apart from the config so they are never written back to it.
+	overrides = viper.New()
 )
 
 func initConfig() {
@@ -83,9 +97,19 @@ func initConfig() {
 	}
 
 	viper.SetConfigType("yaml")
-	viper.SetConfigName(".gocluster")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath(home)
+	if path := overrides.GetString("config"); path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName(".gocluster")
+		viper.AddConfigPath(".")
+		viper.AddConfigPath(home)
+	}
+
+	// 'config validate' reads the file itself so that it can report the
+	// problems that would make loading fail.
+	if validateConfigCmd.CalledAs() != "" {
+		return
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Println("Unable to read config:", err)
@@ -94,13 +118,46 @@ func initConfig() {
 
 	if err := viper.Unmarshal(&config); err != nil {
 		fmt.Println("Unable to decode config:", err)
+		fmt.Println("Run 'gocluster config validate' to find the offending lines.")
+		os.Exit(1)
+	}
+	if err := resolveConfig(viper.GetViper(), &config); err != nil {
+		fmt.Println("Unable to load config:", err)
 		os.Exit(1)
 	}
+	if cluster := overrides.GetString("cluster"); cluster != "" && !calledUnder(cliConfigCmd) && !calledUnder(contextCmd) {
+		if _, ok := config.Clusters[cluster]; !ok {
+			fmt.Printf("Cluster '%s' from --cluster or GOCLUSTER_CLUSTER not found in configuration\n", cluster)
+			os.Exit(1)
+		}
+		config.SelectedCluster = cluster
+	}
+	applyContext()
+}
+
+// calledUnder reports whether the running command is cmd or one of its
+// subcommands.
+func calledUnder(cmd *cobra.Command) bool {
+	if cmd.CalledAs() != "" {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if calledUnder(sub) {
+			return true
+		}
+	}
+	return false
 }
 
 func main() {
 	cobra.OnInitialize(initConfig)
-	if err := rootCmd.Execute(); err != nil {
+
+	// Cancel the command context on Ctrl-C so long-running commands such as
+	// 'logs --follow' can shut down cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -110,14 +167,29 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVar(&parallel, "parallel", true, "Run operations in parallel")
 	rootCmd.PersistentFlags().StringSliceVar(&targetNodes, "nodes", []string{}, "Specific nodes to run operation on (comma-separated)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format ("+strings.Join(outputFormats, "|")+")")
+	rootCmd.PersistentFlags().StringVar(&viaNode, "via", "", "Send requests through this node only, overriding the cluster's strategy")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print the nodes requests are sent to on stderr")
+	rootCmd.PersistentFlags().String("cluster", "", "Run against this cluster instead of the selected one (or set GOCLUSTER_CLUSTER)")
+	overrides.SetEnvPrefix("gocluster")
+	overrides.BindEnv("cluster")
+	overrides.BindEnv("config")
+	overrides.BindPFlag("cluster", rootCmd.PersistentFlags().Lookup("cluster"))
+	rootCmd.RegisterFlagCompletionFunc("cluster", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeClusters(cmd, nil, toComplete)
+	})
+	rootCmd.RegisterFlagCompletionFunc("nodes", completeNodes)
+	rootCmd.RegisterFlagCompletionFunc("via", completeNodes)
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutput)
 
 	// Cluster management commands
 	rootCmd.AddCommand(
 		&cobra.Command{
-			Use:   "use [cluster_name]",
-			Short: "Select a cluster to use",
-			Args:  cobra.ExactArgs(1),
-			Run:   useCluster,
+			Use:               "use [cluster_name]",
+			Short:             "Select a cluster to use",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeClusters,
+			Run:               useCluster,
 		},
 		&cobra.Command{
 			Use:   "which",
@@ -127,22 +199,87 @@ func init() {
 	)
 
 	// Basic commands
-	rootCmd.AddCommand(newCmd("health", "Check cluster health", checkHealth))
-	rootCmd.AddCommand(newCmd("nodes", "List all nodes in the cluster", listNodes))
-	rootCmd.AddCommand(newCmd("leader", "Get current cluster leader", getLeader))
+	healthCmd := newCmd("health", "Check cluster health", checkHealth)
+	nodesCmd := newCmd("nodes", "List all nodes in the cluster", listNodes)
+	leaderCmd := newCmd("leader", "Get current cluster leader", getLeader)
+	for _, cmd := range []*cobra.Command{healthCmd, nodesCmd, leaderCmd} {
+		cmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Re-poll and redraw at --interval, printing an event for every change")
+		cmd.Flags().DurationVar(&watchInterval, "interval", 2*time.Second, "Polling interval in watch mode")
+	}
+	nodesCmd.Flags().DurationVar(&staleAfter, "stale-after", 30*time.Second, "Flag nodes not seen for longer than this in watch mode")
+	rootCmd.AddCommand(healthCmd, nodesCmd, leaderCmd)
 	rootCmd.AddCommand(newCmd("clusters", "Get available clusters", getClusterList))
 
 	// Logs command
 	logsCmd := &cobra.Command{
 		Use:   "logs",
 		Short: "View cluster logs",
-		Run:   viewLogs,
+		Long: `View cluster logs.
+
+By default logs are read from the leader. Use --node for a single node, or
+--all / the global --nodes flag to tail several nodes at once; their lines
+are prefixed with the node ID and merged by timestamp.`,
+		Run: viewLogs,
 	}
 	logsCmd.Flags().StringVarP(&logNode, "node", "n", "", "Node to fetch logs from (defaults to leader)")
+	logsCmd.RegisterFlagCompletionFunc("node", completeNodes)
+	logsCmd.Flags().BoolVarP(&allLogs, "all", "a", false, "Fetch logs from every node in the cluster")
+	logsCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored node prefixes")
+	logsCmd.Flags().StringArrayVar(&logGrep, "grep", nil, "Only show lines matching this regular expression (repeatable)")
+	logsCmd.Flags().StringArrayVar(&logExclude, "exclude", nil, "Hide lines matching this regular expression (repeatable)")
+	logsCmd.Flags().StringVar(&logSince, "since", "", "Only show lines newer than a duration (15m) or timestamp")
+	logsCmd.Flags().StringVar(&logUntil, "until", "", "Only show lines older than a duration (5m) or timestamp")
+	logsCmd.Flags().StringVar(&logLevel, "level", "", "Minimum level of structured log lines (trace|debug|info|warn|error|fatal)")
 	logsCmd.Flags().IntVarP(&logLines, "lines", "l", 100, "Number of log lines to fetch")
 	logsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Stream logs in real-time")
+	logsCmd.Flags().DurationVar(&pollInterval, "poll-interval", 2*time.Second, "Polling interval when the server cannot stream logs")
 	rootCmd.AddCommand(logsCmd)
 
+	// Backup commands
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage cluster backups",
+	}
+	backupCreateCmd := &cobra.Command{
+		Use:   "create [backup_name]",
+		Short: "Create a backup and wait for it to finish",
+		Args:  cobra.ExactArgs(1),
+		Run:   createBackup,
+	}
+	backupCreateCmd.Flags().BoolVar(&backupNoWait, "no-wait", false, "Return as soon as the backup has started")
+	backupRestoreCmd := &cobra.Command{
+		Use:   "restore [backup_name]",
+		Short: "Restore a backup",
+		Args:  cobra.ExactArgs(1),
+		Run:   restoreBackup,
+	}
+	backupDeleteCmd := &cobra.Command{
+		Use:   "delete [backup_name]",
+		Short: "Delete a backup",
+		Args:  cobra.ExactArgs(1),
+		Run:   deleteBackup,
+	}
+	for _, cmd := range []*cobra.Command{backupRestoreCmd, backupDeleteCmd} {
+		cmd.Flags().BoolVarP(&backupYes, "yes", "y", false, "Do not ask for confirmation")
+	}
+	backupCmd.AddCommand(
+		backupCreateCmd,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List backups",
+			Run:   listBackups,
+		},
+		&cobra.Command{
+			Use:   "describe [backup_name]",
+			Short: "Show details and progress of a backup",
+			Args:  cobra.ExactArgs(1),
+			Run:   describeBackup,
+		},
+		backupRestoreCmd,
+		backupDeleteCmd,
+	)
+	rootCmd.AddCommand(backupCmd)
+
 	// Metrics command
 	metricsCmd := &cobra.Command{
 		Use:   "metrics",
@@ -168,9 +305,116 @@ func init() {
 			Args:  cobra.ExactArgs(2),
 			Run:   setConfig,
 		},
+		validateConfigCmd,
 	)
 	rootCmd.AddCommand(configCmd)
 
+	// Local config commands
+	addClusterCmd := &cobra.Command{
+		Use:   "add-cluster [cluster_name]",
+		Short: "Add a cluster",
+		Args:  cobra.ExactArgs(1),
+		Run:   addCluster,
+	}
+	addClusterCmd.Flags().StringToStringVar(&clusterNodes, "node", nil, "Node of the cluster (id=host:port, repeatable)")
+	addClusterCmd.Flags().IntVar(&clusterPort, "port", 0, "Cluster port")
+	addClusterCmd.Flags().StringVar(&clusterStrategy, "strategy", "", "Node selection strategy ("+strings.Join(client.Strategies, "|")+")")
+	cliConfigCmd.AddCommand(
+		&cobra.Command{
+			Use:   "view",
+			Short: "Print the local config file",
+			Run:   viewCLIConfig,
+		},
+		addClusterCmd,
+		&cobra.Command{
+			Use:               "remove-cluster [cluster_name]",
+			Short:             "Remove a cluster",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeClusters,
+			Run:               removeCluster,
+		},
+		&cobra.Command{
+			Use:               "add-node [cluster_name] [node_id] [address]",
+			Short:             "Add a node to a cluster",
+			Args:              cobra.ExactArgs(3),
+			ValidArgsFunction: completeClusters,
+			Run:               addNode,
+		},
+		&cobra.Command{
+			Use:   "remove-node [cluster_name] [node_id]",
+			Short: "Remove a node from a cluster",
+			Args:  cobra.ExactArgs(2),
+			Run:   removeNode,
+		},
+		&cobra.Command{
+			Use:               "rename [cluster_name] [new_name]",
+			Short:             "Rename a cluster",
+			Args:              cobra.ExactArgs(2),
+			ValidArgsFunction: completeClusters,
+			Run:               renameCluster,
+		},
+		&cobra.Command{
+			Use:               "set-default [cluster_name]",
+			Short:             "Set the default cluster",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeClusters,
+			Run:               setDefaultCluster,
+		},
+		&cobra.Command{
+			Use:   "edit",
+			Short: "Open the local config in $EDITOR and validate it on save",
+			Run:   editCLIConfig,
+		},
+	)
+	rootCmd.AddCommand(cliConfigCmd)
+
+	// Context commands
+	createContextCmd := &cobra.Command{
+		Use:   "create [context_name]",
+		Short: "Create a context",
+		Args:  cobra.ExactArgs(1),
+		Run:   createContext,
+	}
+	createContextCmd.Flags().StringVar(&contextCluster, "for-cluster", "", "Cluster the context runs commands against")
+	createContextCmd.MarkFlagRequired("for-cluster")
+	createContextCmd.RegisterFlagCompletionFunc("for-cluster", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeClusters(cmd, nil, toComplete)
+	})
+	createContextCmd.Flags().StringSliceVar(&contextNodes, "default-nodes", nil, "Default for --nodes (comma-separated)")
+	createContextCmd.Flags().StringVar(&contextOutput, "default-output", "", "Default for --output")
+	createContextCmd.Flags().IntVar(&contextTimeout, "timeout", 0, "Request timeout in seconds")
+	createContextCmd.Flags().StringVar(&contextTokenEnv, "token-env", "", "Environment variable holding a bearer token")
+	createContextCmd.Flags().StringVar(&contextUsername, "username", "", "Basic auth user name")
+	createContextCmd.Flags().StringVar(&contextPasswordEnv, "password-env", "", "Environment variable holding the basic auth password")
+	contextCmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List contexts",
+			Run:   listContexts,
+		},
+		&cobra.Command{
+			Use:               "use [context_name]",
+			Short:             "Switch to a context",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeContexts,
+			Run:               useContext,
+		},
+		&cobra.Command{
+			Use:   "current",
+			Short: "Show the current context",
+			Run:   showCurrentContext,
+		},
+		createContextCmd,
+		&cobra.Command{
+			Use:               "delete [context_name]",
+			Short:             "Delete a context",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeContexts,
+			Run:               deleteContext,
+		},
+	)
+	rootCmd.AddCommand(contextCmd)
+
 	// Operator commands
 	operatorCmd := &cobra.Command{
 		Use:   "operator",
@@ -178,35 +422,97 @@ func init() {
 	}
 
 	triggerCmd := &cobra.Command{
-		Use:   "trigger [operator_name] [operation]",
-		Short: "Trigger operator operation",
-		Args:  cobra.ExactArgs(2),
-		Run:   triggerOperator,
+		Use:               "trigger [operator_name] [operation]",
+		Short:             "Trigger operator operation",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeTrigger,
+		Run:               triggerOperator,
 	}
 
 	triggerCmd.Flags().StringToStringP("params", "p", nil, "Operation parameters (key=value)")
 	triggerCmd.Flags().StringToStringP("config", "c", nil, "Config parameters (key=value)")
+	triggerCmd.RegisterFlagCompletionFunc("params", completeParamKeys("params"))
+	triggerCmd.RegisterFlagCompletionFunc("config", completeParamKeys("config"))
+	triggerCmd.Flags().BoolVar(&triggerWait, "wait", false, "Wait for the job to finish and exit with its result code")
+	triggerCmd.Flags().StringVarP(&triggerParamsFile, "params-file", "f", "", "Read parameters from a YAML or JSON file ('-' for stdin); -p overrides")
+	triggerCmd.Flags().StringVar(&triggerConfigFile, "config-file", "", "Read config values from a YAML or JSON file ('-' for stdin); -c overrides")
+	triggerCmd.Flags().BoolVar(&triggerShowResolved, "show-resolved", false, "Print the resolved params and config with the source of each value without executing")
+	triggerCmd.Flags().BoolVar(&triggerDryRun, "dry-run", false, "Validate and print the payload (and the server's plan, if supported) without executing")
+
+	waitCmd := &cobra.Command{
+		Use:   "wait [job_id]",
+		Short: "Wait for an operator job to finish and exit with its result code",
+		Args:  cobra.ExactArgs(1),
+		Run:   operatorWait,
+	}
+	retryCmd := &cobra.Command{
+		Use:   "retry [job_id]",
+		Short: "Trigger a finished operator job again",
+		Args:  cobra.ExactArgs(1),
+		Run:   operatorRetry,
+	}
+	retryCmd.Flags().BoolVar(&retryFailedOnly, "failed-only", false, "Only run on the nodes the job failed on")
+	retryCmd.Flags().BoolVar(&triggerWait, "wait", false, "Wait for the new job to finish and exit with its result code")
+
+	for _, cmd := range []*cobra.Command{triggerCmd, waitCmd, retryCmd} {
+		cmd.Flags().DurationVar(&jobTimeout, "timeout", 0, "Give up waiting after this long (0 waits forever)")
+		cmd.Flags().DurationVar(&jobInterval, "interval", 2*time.Second, "Polling interval while waiting")
+	}
+
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List recent operator jobs",
+		Run:   operatorJobs,
+	}
+	jobsCmd.Flags().StringVar(&jobOperator, "operator", "", "Only show jobs of this operator")
+	jobsCmd.Flags().StringVar(&jobState, "state", "", "Only show jobs in this state (pending|running|completed|failed|cancelled)")
+	jobsCmd.Flags().StringVar(&jobSince, "since", "", "Only show jobs newer than a duration (24h) or timestamp")
+	jobsCmd.Flags().IntVar(&jobLimit, "limit", 20, "Maximum number of jobs to show (0 for all)")
+
+	listOperatorsCmd := &cobra.Command{
+		Use:               "list [operator_name]",
+		Short:             "List available operators or show detailed info for a specific operator",
+		ValidArgsFunction: completeOperators,
+		Run:               listOperators,
+	}
+	showOperatorCmd := &cobra.Command{
+		Use:               "show [operator_name]",
+		Short:             "Show detailed information for a specific operator",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeOperators,
+		Run: func(cmd *cobra.Command, args []string) {
+			cluster, err := getSelectedCluster()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			showOperatorDetails(cmd, cluster, args[0])
+		},
+	}
+	showOperatorCmd.Flags().BoolVar(&schemaOffline, "offline", false, "Only use the cached schema, without contacting the cluster")
+	for _, cmd := range []*cobra.Command{listOperatorsCmd, showOperatorCmd, triggerCmd} {
+		cmd.Flags().BoolVar(&schemaRefresh, "refresh", false, "Fetch the operator schema even if a cached copy is fresh")
+	}
 
 	operatorCmd.AddCommand(
+		listOperatorsCmd,
+		showOperatorCmd,
+		triggerCmd,
 		&cobra.Command{
-			Use:   "list [operator_name]",
-			Short: "List available operators or show detailed info for a specific operator",
-			Run:   listOperators,
+			Use:   "status [job_id]",
+			Short: "Show per-node progress and results of an operator job",
+			Args:  cobra.ExactArgs(1),
+			Run:   operatorStatus,
 		},
+		waitCmd,
+		jobsCmd,
 		&cobra.Command{
-			Use:   "show [operator_name]",
-			Short: "Show detailed information for a specific operator",
+			Use:   "cancel [job_id]",
+			Short: "Cancel a running operator job",
 			Args:  cobra.ExactArgs(1),
-			Run: func(cmd *cobra.Command, args []string) {
-				cluster, err := getSelectedCluster()
-				if err != nil {
-					fmt.Printf("Error: %v\n", err)
-					return
-				}
-				showOperatorDetails(cluster, args[0])
-			},
+			Run:   operatorCancel,
 		},
-		triggerCmd,
+		retryCmd,
 	)
 
 	rootCmd.AddCommand(operatorCmd)
@@ -216,6 +522,36 @@ func newCmd(use, short string, run func(cmd *cobra.Command, args []string)) *cob
 	return &cobra.Command{Use: use, Short: short, Run: run}
 }
 
+// forEachNode calls fn for every node, concurrently unless --parallel=false.
+func forEachNode(nodes []string, fn func(i int, node string)) {
+	if !parallel {
+		for i, node := range nodes {
+			fn(i, node)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			fn(i, node)
+		}(i, node)
+	}
+	wg.Wait()
+}
+
+// sortedKeys returns the keys of m in lexical order so output is stable.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func useCluster(cmd *cobra.Command, args []string) {
 	clusterName := args[0]
 
@@ -227,39 +563,41 @@ func useCluster(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	config.SelectedCluster = clusterName
-	viper.Set("selected_cluster", clusterName)
-
-	configPath := viper.ConfigFileUsed()
-	if err := viper.WriteConfig(); err != nil {
-		if os.IsNotExist(err) {
-			dir := filepath.Dir(configPath)
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				fmt.Printf("Error creating config directory: %v\n", err)
-				return
-			}
-			if err := viper.WriteConfigAs(configPath); err != nil {
-				fmt.Printf("Error saving config: %v\n", err)
-				return
-			}
-		} else {
-			fmt.Printf("Error saving config: %v\n", err)
-			return
-		}
+	f, err := loadConfigFile(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer f.close()
+	previousContext, err := f.selectCluster(clusterName)
+	if err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
 	}
 
 	fmt.Printf("Now using cluster: %s\n", clusterName)
+	if previousContext != "" {
+		fmt.Printf("Context '%s' is no longer active\n", previousContext)
+	}
 }
 
 func getClusterList(cmd *cobra.Command, args []string) {
-	// put it in a neat table format
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Avaliable Clusters"})
-	for name := range config.Clusters {
-		table.Append([]string{name})
-	}
-	table.Render()
-	return
+	l := &listing{
+		kind:       "clusters",
+		header:     []string{"Avaliable Clusters"},
+		wideHeader: []string{"Nodes", "Port"},
+	}
+	clusters := make([]client.ClusterConfig, 0, len(config.Clusters))
+	for _, name := range sortedKeys(config.Clusters) {
+		cluster := config.Clusters[name]
+		cluster.Name = name
+		clusters = append(clusters, cluster)
+		l.rows = append(l.rows, []string{name})
+		l.wideRows = append(l.wideRows, []string{strconv.Itoa(len(cluster.Nodes)), strconv.Itoa(cluster.Port)})
+		l.names = append(l.names, name)
+	}
+	l.data = clusters
+	printListing(l)
 }
 
 func showSelectedCluster(cmd *cobra.Command, args []string) {
@@ -267,10 +605,14 @@ func showSelectedCluster(cmd *cobra.Command, args []string) {
 		fmt.Println("No cluster selected. Use 'gocluster use <cluster_name>' to select a cluster.")
 		return
 	}
+	if currentContext() != nil {
+		fmt.Printf("Currently selected cluster: %s (context %s)\n", config.SelectedCluster, config.CLI.CurrentContext)
+		return
+	}
 	fmt.Printf("Currently selected cluster: %s\n", config.SelectedCluster)
 }
 
-func getSelectedCluster() (*ClusterConfig, error) {
+func getSelectedCluster() (*client.ClusterConfig, error) {
 	if config.SelectedCluster == "" {
 		return nil, fmt.Errorf("no cluster selected. Use 'gocluster use <cluster_name>' to select a cluster")
 	}
@@ -281,78 +623,76 @@ func getSelectedCluster() (*ClusterConfig, error) {
 	return &cluster, nil
 }
 
-// New command implementations
-func viewLogs(cmd *cobra.Command, args []string) {
-	cluster, err := getSelectedCluster()
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
+// newClient returns an API client for the given cluster using the global
+// request settings.
+func newClient(cluster *client.ClusterConfig) *client.Client {
+	selected := *cluster
+	if viaNode != "" {
+		selected.Strategy, selected.Via = client.StrategyPinned, viaNode
 	}
 
-	targetNode := logNode
-	if targetNode == "" {
-		// Get leader node if no specific node is specified
-		resp, err := fetchFromAPI(cluster, "leader")
-		if err != nil {
-			fmt.Printf("Error fetching leader: %v\n", err)
-			return
-		}
-		leader, ok := resp.Data.(map[string]interface{})
-		if !ok {
-			fmt.Println("Invalid response format for leader")
-			return
-		}
-		targetNode = leader["id"].(string)
+	opts := []client.Option{
+		client.WithTimeout(time.Duration(config.Timeout) * time.Second),
+		client.WithRetries(config.Retries),
+		client.WithCredentials(contextCredentials()),
 	}
-
-	endpoint := fmt.Sprintf("logs/%s?lines=%d", targetNode, logLines)
-	if followLogs {
-		endpoint += "&follow=true"
+	if verbose {
+		opts = append(opts, client.WithLogf(func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, "* "+format+"\n", args...)
+		}))
 	}
+	return client.New(selected, opts...)
+}
 
-	resp, err := fetchFromAPI(cluster, endpoint)
+// New command implementations
+func showOperatorDetails(cmd *cobra.Command, cluster *client.ClusterConfig, operatorName string) {
+	schema, err := fetchOperatorSchema(cmd.Context(), newClient(cluster), operatorName)
 	if err != nil {
-		fmt.Printf("Error fetching logs: %v\n", err)
-		return
-	}
-
-	logs, ok := resp.Data.([]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for logs")
+		fmt.Printf("Error fetching operator details: %v\n", err)
 		return
 	}
 
-	for _, log := range logs {
-		fmt.Println(log)
+	l := &listing{
+		data:   schema,
+		header: []string{"Operation", "Parameter", "Kind", "Type", "Required", "Default", "Description"},
+		table: func(w io.Writer, wide bool) {
+			renderOperatorDetails(w, schema)
+		},
 	}
-
-	if followLogs {
-		fmt.Println("Log streaming not implemented yet")
+	for _, opName := range sortedKeys(schema.Operations) {
+		opSchema := schema.Operations[opName]
+		l.names = append(l.names, opName)
+		for _, kind := range []string{"param", "config"} {
+			params := opSchema.Parameters
+			if kind == "config" {
+				params = opSchema.Config
+			}
+			for _, name := range sortedKeys(params) {
+				param := params[name]
+				l.rows = append(l.rows, []string{opName, name, kind, formatParamType(param), strconv.FormatBool(param.Required), formatDefault(param.Default), param.Description})
+			}
+		}
 	}
+	printListing(l)
 }
 
-func showOperatorDetails(cluster *ClusterConfig, operatorName string) {
-	schema, err := fetchOperatorSchema(cluster, operatorName)
-	if err != nil {
-		fmt.Printf("Error fetching operator details: %v\n", err)
-		return
-	}
-
-	fmt.Printf("\nOperator: %s\n", schema.Name)
-	fmt.Printf("Version:     %s\n", schema.Version)
-	fmt.Printf("Description: %s\n\n", schema.Description)
+func renderOperatorDetails(w io.Writer, schema *client.OperatorSchema) {
+	fmt.Fprintf(w, "\nOperator: %s\n", schema.Name)
+	fmt.Fprintf(w, "Version:     %s\n", schema.Version)
+	fmt.Fprintf(w, "Description: %s\n\n", schema.Description)
 
-	fmt.Println("Available Operations")
-	fmt.Println("-------------------")
+	fmt.Fprintln(w, "Available Operations")
+	fmt.Fprintln(w, "-------------------")
 
-	for opName, opSchema := range schema.Operations {
-		fmt.Printf("\n%s\n", opName)
-		fmt.Printf("%s\n", opSchema.Description)
+	for _, opName := range sortedKeys(schema.Operations) {
+		opSchema := schema.Operations[opName]
+		fmt.Fprintf(w, "\n%s\n", opName)
+		fmt.Fprintf(w, "%s\n", opSchema.Description)
 
 		// Parameters table
 		if len(opSchema.Parameters) > 0 {
-			fmt.Println("\nParameters:")
-			paramTable := tablewriter.NewWriter(os.Stdout)
+			fmt.Fprintln(w, "\nParameters:")
+			paramTable := tablewriter.NewWriter(w)
 			paramTable.SetHeader([]string{"Name", "Type", "Required", "Default", "Description"})
 			paramTable.SetColumnAlignment([]int{
 				tablewriter.ALIGN_LEFT,
@@ -362,23 +702,27 @@ func showOperatorDetails(cluster *ClusterConfig, operatorName string) {
 				tablewriter.ALIGN_LEFT,
 			})
 
-			for name, param := range opSchema.Parameters {
-				defaultVal := "nil"
-				if param.Default != nil {
-					defaultVal = fmt.Sprintf("%v", param.Default)
-				}
+			for _, name := range sortedKeys(opSchema.Parameters) {
+				param := opSchema.Parameters[name]
 				paramTable.Append([]string{
 					name,
-					param.Type,
+					formatParamType(param),
 					fmt.Sprintf("%v", param.Required),
-					defaultVal,
+					formatDefault(param.Default),
 					param.Description,
 				})
 			}
 			paramTable.Render()
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
+}
+
+func formatDefault(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%v", value)
 }
 
 func checkHealth(cmd *cobra.Command, args []string) {
@@ -387,242 +731,247 @@ func checkHealth(cmd *cobra.Command, args []string) {
 		fmt.Println("Error:", err)
 		return
 	}
+	c := newClient(cluster)
 
-	resp, err := fetchFromAPI(cluster, "health")
-	if err != nil {
-		fmt.Println("Error checking health:", err)
+	if watchMode {
+		watch(cmd.Context(), "gocluster health", func(ctx context.Context) (*snapshot, error) {
+			return healthSnapshot(ctx, c, cluster), nil
+		})
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Node", "Status", "Address"})
-	for node, addr := range cluster.Nodes {
-		status := "Healthy"
-		if resp.Success == false {
-			status = "Unhealthy"
+	snap := healthSnapshot(cmd.Context(), c, cluster)
+	printListing(snap.listing)
+
+	// Exit non-zero so scripts and cron jobs notice unhealthy nodes.
+	for _, state := range snap.states {
+		if state != "Healthy" {
+			os.Exit(1)
 		}
-		table.Append([]string{node, status, addr})
 	}
-	table.Render()
 }
 
-func listNodes(cmd *cobra.Command, args []string) {
-	cluster, err := getSelectedCluster()
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
+// healthSnapshot probes every selected node and tracks each node's status.
+func healthSnapshot(ctx context.Context, c *client.Client, cluster *client.ClusterConfig) *snapshot {
+	nodes := targetNodes
+	if len(nodes) == 0 {
+		nodes = sortedKeys(cluster.Nodes)
 	}
 
-	resp, err := fetchFromAPI(cluster, "nodes")
-	if err != nil {
-		fmt.Println("Error fetching nodes:", err)
-		return
-	}
+	probes := make([]client.HealthProbe, len(nodes))
+	forEachNode(nodes, func(i int, node string) {
+		probes[i] = c.ProbeHealth(ctx, node)
+	})
 
-	nodes, ok := resp.Data.([]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for nodes")
-		return
+	type nodeHealth struct {
+		client.HealthProbe
+		Status    string  `json:"status"`
+		LatencyMS float64 `json:"latency_ms"`
+	}
+	results := make([]nodeHealth, 0, len(probes))
+	snap := &snapshot{
+		listing: &listing{
+			kind:       "health",
+			header:     []string{"Node", "Status", "Address", "Latency", "Error"},
+			wideHeader: []string{"HTTP Code"},
+		},
+		states: map[string]string{},
 	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Node ID", "Address", "Age", "State"})
-	for _, nodeData := range nodes {
-		nodeMap, ok := nodeData.(map[string]interface{})
-		if !ok {
-			fmt.Println("Invalid node data format")
-			continue
+	l := snap.listing
+	for _, probe := range probes {
+		status := "Healthy"
+		if !probe.Healthy {
+			status = "Unhealthy"
 		}
+		code := ""
+		if probe.StatusCode != 0 {
+			code = strconv.Itoa(probe.StatusCode)
+		}
+		results = append(results, nodeHealth{
+			HealthProbe: probe,
+			Status:      status,
+			LatencyMS:   float64(probe.Latency.Microseconds()) / 1000,
+		})
+		l.rows = append(l.rows, []string{probe.Node, status, probe.Address, formatLatency(probe.Latency), probe.Error})
+		l.wideRows = append(l.wideRows, []string{code})
+		l.names = append(l.names, probe.Node)
+		snap.rowKeys = append(snap.rowKeys, probe.Node)
+		snap.states[probe.Node] = status
+	}
+	l.data = results
+	return snap
+}
 
-		id, _ := nodeMap["id"].(string)
-		address, _ := nodeMap["address"].(string)
-		lastSeen, _ := time.Parse(time.RFC3339Nano, nodeMap["last_seen"].(string))
-		age := humanize.Time(lastSeen)
-		state, _ := nodeMap["state"].(string)
-
-		table.Append([]string{id, address, age, state})
+// formatLatency rounds a latency for display.
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
 	}
-	table.Render()
+	return d.Round(time.Millisecond).String()
 }
 
-func getLeader(cmd *cobra.Command, args []string) {
+func listNodes(cmd *cobra.Command, args []string) {
 	cluster, err := getSelectedCluster()
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
+	c := newClient(cluster)
 
-	resp, err := fetchFromAPI(cluster, "leader")
-	if err != nil {
-		fmt.Println("Error fetching leader:", err)
-		return
-	}
-
-	leader, ok := resp.Data.(map[string]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for leader")
+	if watchMode {
+		watch(cmd.Context(), "gocluster nodes", func(ctx context.Context) (*snapshot, error) {
+			return nodesSnapshot(ctx, c)
+		})
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Leader ID", "Address"})
-	table.Append([]string{leader["id"].(string), leader["address"].(string)})
-	table.Render()
-}
-
-func listOperators(cmd *cobra.Command, args []string) {
-	cluster, err := getSelectedCluster()
+	snap, err := nodesSnapshot(cmd.Context(), c)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
-	}
-
-	// Check if we're showing detailed info for a specific operator
-	if len(args) > 0 {
-		showOperatorDetails(cluster, args[0])
+		fmt.Println("Error fetching nodes:", err)
 		return
 	}
+	printListing(snap.listing)
+}
 
-	resp, err := fetchFromAPI(cluster, "operator/list")
+// nodesSnapshot lists the cluster members and tracks each node's state,
+// flagging nodes not seen for longer than --stale-after.
+func nodesSnapshot(ctx context.Context, c *client.Client) (*snapshot, error) {
+	nodes, err := c.Nodes(ctx)
 	if err != nil {
-		fmt.Printf("Error fetching operators: %v\n", err)
-		return
+		return nil, err
 	}
 
-	operators, ok := resp.Data.([]interface{})
-	if !ok {
-		fmt.Println("Invalid response format")
-		return
+	snap := &snapshot{
+		listing: &listing{
+			data:       nodes,
+			kind:       "nodes",
+			header:     []string{"Node ID", "Address", "Age", "State"},
+			wideHeader: []string{"Last Seen"},
+		},
+		states: map[string]string{},
 	}
+	l := snap.listing
+	for _, node := range nodes {
+		l.rows = append(l.rows, []string{node.ID, node.Address, humanize.Time(node.LastSeen), node.State})
+		l.wideRows = append(l.wideRows, []string{node.LastSeen.Format(time.RFC3339)})
+		l.names = append(l.names, node.ID)
 
-	// Create and configure table for summary view
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Version", "Author", "Description"})
-	table.SetAutoWrapText(false)
-	table.SetColumnAlignment([]int{
-		tablewriter.ALIGN_LEFT,
-		tablewriter.ALIGN_CENTER,
-		tablewriter.ALIGN_LEFT,
-		tablewriter.ALIGN_LEFT,
-	})
-
-	for _, op := range operators {
-		operator := op.(map[string]interface{})
-		table.Append([]string{
-			operator["name"].(string),
-			operator["version"].(string),
-			operator["author"].(string),
-			operator["description"].(string),
-		})
+		state := node.State
+		if staleAfter > 0 && time.Since(node.LastSeen) > staleAfter {
+			state += " (stale)"
+		}
+		snap.rowKeys = append(snap.rowKeys, node.ID)
+		snap.states[node.ID] = state
 	}
-
-	fmt.Println("\nAvailable Operators")
-	fmt.Println("Use 'gocluster operator show <name>' for detailed information")
-	fmt.Println()
-	table.Render()
+	return snap, nil
 }
 
-func viewMetrics(cmd *cobra.Command, args []string) {
+func getLeader(cmd *cobra.Command, args []string) {
 	cluster, err := getSelectedCluster()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
-	}
-
-	resp, err := fetchFromAPI(cluster, "metrics")
-	if err != nil {
-		fmt.Printf("Error fetching metrics: %v\n", err)
+		fmt.Println("Error:", err)
 		return
 	}
+	c := newClient(cluster)
 
-	metrics, ok := resp.Data.(map[string]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for metrics")
+	if watchMode {
+		watch(cmd.Context(), "gocluster leader", func(ctx context.Context) (*snapshot, error) {
+			return leaderSnapshot(ctx, c)
+		})
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Metric", "Value"})
-
-	for metric, value := range metrics {
-		table.Append([]string{metric, fmt.Sprintf("%v", value)})
-	}
-	table.Render()
-}
-
-func createBackup(cmd *cobra.Command, args []string) {
-	cluster, err := getSelectedCluster()
+	snap, err := leaderSnapshot(cmd.Context(), c)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Error fetching leader:", err)
 		return
 	}
+	printListing(snap.listing)
+}
 
-	backupName := args[0]
-	resp, err := fetchFromAPI(cluster, fmt.Sprintf("backup/create/%s", backupName))
+// leaderSnapshot fetches the current leader.
+func leaderSnapshot(ctx context.Context, c *client.Client) (*snapshot, error) {
+	leader, err := c.Leader(ctx)
 	if err != nil {
-		fmt.Printf("Error creating backup: %v\n", err)
-		return
+		return nil, err
 	}
 
-	if resp.Success {
-		fmt.Printf("Backup '%s' created successfully\n", backupName)
-	} else {
-		fmt.Printf("Failed to create backup: %s\n", resp.Error)
-	}
+	return &snapshot{
+		listing: &listing{
+			data:   leader,
+			header: []string{"Leader ID", "Address"},
+			rows:   [][]string{{leader.ID, leader.Address}},
+			names:  []string{leader.ID},
+		},
+		states:  map[string]string{"leader": leader.ID},
+		rowKeys: []string{"leader"},
+	}, nil
 }
 
-func listBackups(cmd *cobra.Command, args []string) {
+func listOperators(cmd *cobra.Command, args []string) {
 	cluster, err := getSelectedCluster()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
-	resp, err := fetchFromAPI(cluster, "backup/list")
-	if err != nil {
-		fmt.Printf("Error listing backups: %v\n", err)
+	// Check if we're showing detailed info for a specific operator
+	if len(args) > 0 {
+		showOperatorDetails(cmd, cluster, args[0])
 		return
 	}
 
-	backups, ok := resp.Data.([]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for backups")
+	operators, err := newClient(cluster).OperatorList(cmd.Context())
+	if err != nil {
+		fmt.Printf("Error fetching operators: %v\n", err)
 		return
 	}
+	writeCache(config.SelectedCluster, "operators", operators)
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Size", "Created At"})
-
-	for _, backup := range backups {
-		b := backup.(map[string]interface{})
-		table.Append([]string{
-			b["name"].(string),
-			b["size"].(string),
-			b["created_at"].(string),
+	l := &listing{
+		data:   operators,
+		kind:   "operators",
+		header: []string{"Name", "Version", "Author", "Description"},
+		noWrap: true,
+		align: []int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_CENTER,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	}
+	for _, operator := range operators {
+		l.rows = append(l.rows, []string{
+			operator.Name,
+			operator.Version,
+			operator.Author,
+			operator.Description,
 		})
+		l.names = append(l.names, operator.Name)
+	}
+
+	if isTableOutput() {
+		fmt.Println("\nAvailable Operators")
+		fmt.Println("Use 'gocluster operator show <name>' for detailed information")
+		fmt.Println()
 	}
-	table.Render()
+	printListing(l)
 }
 
-func restoreBackup(cmd *cobra.Command, args []string) {
+func viewMetrics(cmd *cobra.Command, args []string) {
 	cluster, err := getSelectedCluster()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
-	backupName := args[0]
-	resp, err := fetchFromAPI(cluster, fmt.Sprintf("backup/restore/%s", backupName))
+	metrics, err := newClient(cluster).Metrics(cmd.Context())
 	if err != nil {
-		fmt.Printf("Error restoring backup: %v\n", err)
+		fmt.Printf("Error fetching metrics: %v\n", err)
 		return
 	}
 
-	if resp.Success {
-		fmt.Printf("Backup '%s' restored successfully\n", backupName)
-	} else {
-		fmt.Printf("Failed to restore backup: %s\n", resp.Error)
-	}
+	printListing(keyValueListing(metrics, "Metric"))
 }
 
 func viewConfig(cmd *cobra.Command, args []string) {
@@ -632,273 +981,38 @@ func viewConfig(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	resp, err := fetchFromAPI(cluster, "config")
+	config, err := newClient(cluster).ConfigGet(cmd.Context())
 	if err != nil {
 		fmt.Printf("Error fetching config: %v\n", err)
 		return
 	}
 
-	config, ok := resp.Data.(map[string]interface{})
-	if !ok {
-		fmt.Println("Invalid response format for config")
-		return
-	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Key", "Value"})
-
-	for key, value := range config {
-		table.Append([]string{key, fmt.Sprintf("%v", value)})
-	}
-	table.Render()
+	printListing(keyValueListing(config, "Key"))
 }
 
-func setConfig(cmd *cobra.Command, args []string) {
-	cluster, err := getSelectedCluster()
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
-	}
-
-	key := args[0]
-	value := args[1]
-
-	payload := map[string]string{
-		"key":   key,
-		"value": value,
+// keyValueListing renders a free-form map as sorted key/value rows.
+func keyValueListing(values map[string]interface{}, keyHeader string) *listing {
+	l := &listing{
+		data:   values,
+		header: []string{keyHeader, "Value"},
 	}
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("Error encoding payload: %v\n", err)
-		return
-	}
-
-	var nodeAddr string
-	for _, addr := range cluster.Nodes {
-		nodeAddr = addr
-		break
-	}
-
-	client := &http.Client{}
-	req, err := http.NewRequest("POST",
-		fmt.Sprintf("http://%s/api/config/set", nodeAddr),
-		bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		return
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error sending request: %v\n", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		fmt.Printf("Error decoding response: %v\n", err)
-		return
-	}
-
-	if apiResp.Success {
-		fmt.Printf("Configuration updated successfully\n")
-	} else {
-		fmt.Printf("Failed to update configuration: %s\n", apiResp.Error)
+	for _, key := range sortedKeys(values) {
+		l.rows = append(l.rows, []string{key, fmt.Sprintf("%v", values[key])})
+		l.names = append(l.names, key)
 	}
+	return l
 }
 
-// Modified triggerOperator to use global flags
-func triggerOperator(cmd *cobra.Command, args []string) {
-	operatorName := args[0]
-	operationName := args[1]
-
+func setConfig(cmd *cobra.Command, args []string) {
 	cluster, err := getSelectedCluster()
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	schema, err := fetchOperatorSchema(cluster, operatorName)
-	if err != nil {
-		fmt.Println("Error fetching operator schema:", err)
-		return
-	}
-
-	opSchema, exists := schema.Operations[operationName]
-	if !exists {
-		fmt.Printf("Operation '%s' not found for operator '%s'\n", operationName, operatorName)
-		fmt.Println("\nAvailable operations:")
-		for op := range schema.Operations {
-			fmt.Printf("- %s\n", op)
-		}
-		return
-	}
-
-	params, _ := cmd.Flags().GetStringToString("params")
-	config, _ := cmd.Flags().GetStringToString("config")
-
-	validatedParams, err := validateAndConvertParams(params, opSchema.Parameters)
-	if err != nil {
-		fmt.Printf("Parameter validation error: %v\n", err)
-		fmt.Println("\nRequired parameters:")
-		for name, param := range opSchema.Parameters {
-			if param.Required {
-				fmt.Printf("- %s (%s): %s\n", name, param.Type, param.Description)
-			}
-		}
-		return
-	}
-
-	validatedConfig, err := validateAndConvertParams(config, opSchema.Config)
-	if err != nil {
-		fmt.Printf("Config validation error: %v\n", err)
-		return
-	}
-
-	payload := OperatorPayload{
-		Operation:   operationName,
-		Params:      validatedParams,
-		Config:      validatedConfig,
-		Parallel:    parallel,
-		TargetNodes: targetNodes,
-	}
-
-	var nodeAddr string
-	for _, addr := range cluster.Nodes {
-		nodeAddr = addr
-		break
-	}
-
-	client := &http.Client{}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Println("Error encoding payload:", err)
-		return
-	}
-
-	req, err := http.NewRequest("POST",
-		fmt.Sprintf("http://%s/api/operator/trigger/%s", nodeAddr, operatorName),
-		bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error sending request:", err)
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	var apiResp APIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		fmt.Println("Error decoding response:", err)
+	if err := newClient(cluster).ConfigSet(cmd.Context(), args[0], args[1]); err != nil {
+		fmt.Printf("Failed to update configuration: %v\n", err)
 		return
 	}
-
-	if !apiResp.Success {
-		fmt.Printf("Failed to trigger operation: %s\n", apiResp.Error)
-	} else {
-		fmt.Println("Operation triggered successfully")
-		if responseData, ok := apiResp.Data.(map[string]interface{}); ok {
-			if jobID, exists := responseData["job_id"]; exists {
-				fmt.Printf("Job ID: %v\n", jobID)
-				fmt.Println("Use 'gocluster operator status <job_id>' to check the status")
-			}
-		}
-	}
-}
-
-func validateAndConvertParams(params map[string]string, schema map[string]ParamSchema) (map[string]interface{}, error) {
-	result := make(map[string]interface{})
-
-	// Check for required parameters
-	for name, paramSchema := range schema {
-		if paramSchema.Required {
-			if _, exists := params[name]; !exists {
-				if paramSchema.Default != nil {
-					result[name] = paramSchema.Default
-				} else {
-					return nil, fmt.Errorf("required parameter '%s' is missing", name)
-				}
-			}
-		}
-	}
-
-	// Convert and validate provided parameters
-	for name, value := range params {
-		paramSchema, exists := schema[name]
-		if !exists {
-			return nil, fmt.Errorf("unknown parameter '%s'", name)
-		}
-
-		converted, err := convertValue(value, paramSchema.Type)
-		if err != nil {
-			return nil, fmt.Errorf("parameter '%s': %v", name, err)
-		}
-		result[name] = converted
-	}
-
-	return result, nil
-}
-
-func convertValue(value string, targetType string) (interface{}, error) {
-	switch targetType {
-	case "string":
-		return value, nil
-	case "int":
-		return strconv.Atoi(value)
-	case "bool":
-		return strconv.ParseBool(value)
-	case "float":
-		return strconv.ParseFloat(value, 64)
-	default:
-		return nil, fmt.Errorf("unsupported type: %s", targetType)
-	}
-}
-
-func fetchFromAPI(cluster *ClusterConfig, endpoint string) (*APIResponse, error) {
-	var lastErr error
-	for _, addr := range cluster.Nodes {
-		client := &http.Client{Timeout: time.Duration(config.Timeout) * time.Second}
-		resp, err := client.Get(fmt.Sprintf("http://%s/api/%s", addr, endpoint))
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
-		var apiResp APIResponse
-		if err := json.Unmarshal(body, &apiResp); err != nil {
-			lastErr = err
-			continue
-		}
-		return &apiResp, nil
-	}
-	return nil, fmt.Errorf("failed to fetch from any node: %v", lastErr)
-}
-
-func fetchOperatorSchema(cluster *ClusterConfig, operatorName string) (*OperatorSchema, error) {
-	resp, err := fetchFromAPI(cluster, fmt.Sprintf("operator/schema/%s", operatorName))
-	if err != nil {
-		return nil, err
-	}
-
-	schemaData, err := json.Marshal(resp.Data)
-	if err != nil {
-		return nil, err
-	}
-
-	var schema OperatorSchema
-	if err := json.Unmarshal(schemaData, &schema); err != nil {
-		return nil, err
-	}
-
-	return &schema, nil
+	fmt.Printf("Configuration updated successfully\n")
 }
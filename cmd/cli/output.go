@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by -o/--output
+const (
+	outputTable = "table"
+	outputWide  = "wide"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputName  = "name"
+	outputTSV   = "tsv"
+
+	outputGoTemplate     = "go-template"
+	outputGoTemplateFile = "go-template-file"
+	outputJSONPath       = "jsonpath"
+	outputJSONPathFile   = "jsonpath-file"
+)
+
+var outputFormats = []string{
+	outputTable, outputWide, outputJSON, outputYAML, outputName, outputTSV,
+	outputGoTemplate + "=...", outputGoTemplateFile + "=...",
+	outputJSONPath + "=...", outputJSONPathFile + "=...",
+}
+
+// listing is the result of a read command, ready to be rendered by a Printer.
+type listing struct {
+	// data is serialized as-is by the json and yaml printers, so its field
+	// names must match the API payloads.
+	data interface{}
+
+	// kind names the resource listed, e.g. "nodes". JSONPath templates see
+	// list results wrapped under this key, so '{.nodes[*].address}' works.
+	kind string
+
+	header []string
+	rows   [][]string
+	align  []int
+	noWrap bool
+	// highlight marks rows drawn in bold yellow, e.g. rows that changed
+	// since the previous poll in watch mode.
+	highlight []bool
+
+	// wideHeader and wideRows are extra columns appended in wide and tsv mode.
+	wideHeader []string
+	wideRows   [][]string
+
+	names []string
+
+	// table replaces the default table rendering when set.
+	table func(w io.Writer, wide bool)
+}
+
+// Printer renders a listing in one output format.
+type Printer interface {
+	Print(w io.Writer, l *listing) error
+}
+
+type tablePrinter struct{ wide bool }
+type jsonPrinter struct{}
+type yamlPrinter struct{}
+type namePrinter struct{}
+type tsvPrinter struct{}
+type templatePrinter struct{ tmpl *template.Template }
+type jsonPathPrinter struct{ path *jsonPath }
+
+func newPrinter(format string) (Printer, error) {
+	if name, arg, ok := strings.Cut(format, "="); ok {
+		return newTemplatePrinter(name, arg)
+	}
+
+	switch format {
+	case "", outputTable:
+		return tablePrinter{}, nil
+	case outputWide:
+		return tablePrinter{wide: true}, nil
+	case outputJSON:
+		return jsonPrinter{}, nil
+	case outputYAML:
+		return yamlPrinter{}, nil
+	case outputName:
+		return namePrinter{}, nil
+	case outputTSV:
+		return tsvPrinter{}, nil
+	case outputGoTemplate, outputGoTemplateFile, outputJSONPath, outputJSONPathFile:
+		return nil, fmt.Errorf("output format %s requires a template, e.g. -o %s=...", format, format)
+	default:
+		return nil, fmt.Errorf("unknown output format %q (valid formats: %s)", format, strings.Join(outputFormats, ", "))
+	}
+}
+
+// newTemplatePrinter builds a go-template or jsonpath printer from an
+// inline template or, for the -file variants, a template file.
+func newTemplatePrinter(format, arg string) (Printer, error) {
+	text := arg
+	switch format {
+	case outputGoTemplateFile, outputJSONPathFile:
+		raw, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("reading template: %w", err)
+		}
+		text = string(raw)
+	case outputGoTemplate, outputJSONPath:
+	default:
+		return nil, fmt.Errorf("unknown output format %q (valid formats: %s)", format, strings.Join(outputFormats, ", "))
+	}
+
+	switch format {
+	case outputGoTemplate, outputGoTemplateFile:
+		tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing go-template: %w", err)
+		}
+		return templatePrinter{tmpl: tmpl}, nil
+	default:
+		path, err := parseJSONPath(text)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathPrinter{path: path}, nil
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		raw, err := json.Marshal(v)
+		return string(raw), err
+	},
+	"join": func(sep string, v []interface{}) string {
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatJPValue(item)
+		}
+		return strings.Join(parts, sep)
+	},
+}
+
+// printListing renders l to stdout in the format selected with --output.
+func printListing(l *listing) {
+	printer, err := newPrinter(outputFormat)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := printer.Print(os.Stdout, l); err != nil {
+		fmt.Printf("Error rendering output: %v\n", err)
+	}
+}
+
+// isTableOutput reports whether the human readable table output is selected,
+// so commands know when decorations around the table may be printed.
+func isTableOutput() bool {
+	return outputFormat == "" || outputFormat == outputTable || outputFormat == outputWide
+}
+
+func (p tablePrinter) Print(w io.Writer, l *listing) error {
+	if l.table != nil {
+		l.table(w, p.wide)
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w)
+	header, rows := l.header, l.rows
+	if p.wide {
+		header, rows = l.wideColumns()
+	}
+	table.SetHeader(header)
+	table.SetAutoWrapText(!l.noWrap)
+	if len(l.align) > 0 {
+		align := append([]int{}, l.align...)
+		for len(align) < len(header) {
+			align = append(align, tablewriter.ALIGN_DEFAULT)
+		}
+		table.SetColumnAlignment(align)
+	}
+	for i, row := range rows {
+		if i < len(l.highlight) && l.highlight[i] {
+			colors := make([]tablewriter.Colors, len(row))
+			for j := range colors {
+				colors[j] = tablewriter.Colors{tablewriter.Bold, tablewriter.FgYellowColor}
+			}
+			table.Rich(row, colors)
+			continue
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
+
+func (jsonPrinter) Print(w io.Writer, l *listing) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l.data)
+}
+
+func (yamlPrinter) Print(w io.Writer, l *listing) error {
+	// Round-trip through JSON so YAML keys are the API field names.
+	generic, err := toGeneric(l.data)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (namePrinter) Print(w io.Writer, l *listing) error {
+	for _, name := range l.names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tsvPrinter) Print(w io.Writer, l *listing) error {
+	_, rows := l.wideColumns()
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p templatePrinter) Print(w io.Writer, l *listing) error {
+	// Templates address fields by their API names, not the Go field names.
+	data, err := toGeneric(l.data)
+	if err != nil {
+		return err
+	}
+	return p.tmpl.Execute(w, data)
+}
+
+func (p jsonPathPrinter) Print(w io.Writer, l *listing) error {
+	data, err := toGeneric(l.data)
+	if err != nil {
+		return err
+	}
+	if list, ok := data.([]interface{}); ok && l.kind != "" {
+		data = map[string]interface{}{l.kind: list}
+	}
+	var buf bytes.Buffer
+	if err := p.path.execute(&buf, data); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// wideColumns returns the header and rows with the wide columns appended.
+func (l *listing) wideColumns() ([]string, [][]string) {
+	if len(l.wideHeader) == 0 {
+		return l.header, l.rows
+	}
+	header := append(append([]string{}, l.header...), l.wideHeader...)
+	rows := make([][]string, len(l.rows))
+	for i, row := range l.rows {
+		rows[i] = append([]string{}, row...)
+		if i < len(l.wideRows) {
+			rows[i] = append(rows[i], l.wideRows[i]...)
+		}
+	}
+	return header, rows
+}
+
+// toGeneric converts v into maps, slices and scalars using its JSON encoding.
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testNode struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+func TestTemplatePrinters(t *testing.T) {
+	dir := t.TempDir()
+	goTemplateFile := filepath.Join(dir, "nodes.tmpl")
+	if err := os.WriteFile(goTemplateFile, []byte(`{{range .}}{{.id}} {{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPathFile := filepath.Join(dir, "nodes.jsonpath")
+	if err := os.WriteFile(jsonPathFile, []byte(`{.nodes[-1].id}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := &listing{
+		kind: "nodes",
+		data: []testNode{{ID: "node001", Address: "10.0.0.1:8080"}, {ID: "node002", Address: "10.0.0.2:8080"}},
+	}
+	leader := &listing{kind: "leader", data: testNode{ID: "node001", Address: "10.0.0.1:8080"}}
+
+	tests := []struct {
+		name   string
+		format string
+		l      *listing
+		want   string
+	}{
+		{name: "go-template uses API names", format: `go-template={{range .}}{{.id}}={{.address}};{{end}}`, l: nodes, want: "node001=10.0.0.1:8080;node002=10.0.0.2:8080;"},
+		{name: "go-template json func", format: `go-template={{json (index . 0)}}`, l: nodes, want: `{"address":"10.0.0.1:8080","id":"node001"}`},
+		{name: "go-template join func", format: `go-template={{join "," .}}`, l: nodes, want: `{"address":"10.0.0.1:8080","id":"node001"},{"address":"10.0.0.2:8080","id":"node002"}`},
+		{name: "go-template-file", format: "go-template-file=" + goTemplateFile, l: nodes, want: "node001 node002 "},
+		{name: "jsonpath wraps lists under the kind", format: "jsonpath={.nodes[*].id}", l: nodes, want: "node001 node002\n"},
+		{name: "jsonpath keeps its own newline", format: `jsonpath={range .nodes[*]}{.id}{"\n"}{end}`, l: nodes, want: "node001\nnode002\n"},
+		{name: "jsonpath on an object", format: "jsonpath={.address}", l: leader, want: "10.0.0.1:8080\n"},
+		{name: "jsonpath empty result", format: "jsonpath={.nodes[5].id}", l: nodes, want: ""},
+		{name: "jsonpath-file", format: "jsonpath-file=" + jsonPathFile, l: nodes, want: "node002\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPrinter(tt.format)
+			if err != nil {
+				t.Fatalf("newPrinter(%q): %v", tt.format, err)
+			}
+			var buf bytes.Buffer
+			if err := p.Print(&buf, tt.l); err != nil {
+				t.Fatalf("Print: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPrinterErrors(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr string
+	}{
+		{format: "xml", wantErr: `unknown output format "xml"`},
+		{format: "csv=x", wantErr: `unknown output format "csv"`},
+		{format: "jsonpath", wantErr: "requires a template"},
+		{format: "go-template", wantErr: "requires a template"},
+		{format: "go-template={{.id", wantErr: "parsing go-template"},
+		{format: "jsonpath={.id", wantErr: "unclosed '{'"},
+		{format: "jsonpath-file=/nonexistent/template", wantErr: "reading template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			_, err := newPrinter(tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
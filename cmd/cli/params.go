@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	humanize "github.com/dustin/go-humanize"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a resolved parameter value, highest precedence first.
+const (
+	sourcePrompt  = "prompt"
+	sourceFlag    = "flag"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceContext = "context"
+	sourceDefault = "default"
+)
+
+// Environment variable prefixes for operation params and config, e.g.
+// GOCLUSTER_PARAM_REPLICATION_FACTOR.
+const (
+	paramEnvPrefix  = "GOCLUSTER_PARAM_"
+	configEnvPrefix = "GOCLUSTER_OPERATOR_CONFIG_"
+)
+
+// paramLayer holds the values given for an operation by one source.
+type paramLayer struct {
+	source string
+	values map[string]interface{}
+}
+
+// validateAndConvertParams resolves every parameter of the schema from the
+// layers, which are ordered by precedence, and falls back to the schema
+// default. It returns the converted values and the source of each.
+func validateAndConvertParams(layers []paramLayer, schema map[string]client.ParamSchema) (map[string]interface{}, map[string]string, error) {
+	for _, layer := range layers {
+		for _, name := range sortedKeys(layer.values) {
+			if _, exists := schema[name]; !exists {
+				return nil, nil, fmt.Errorf("unknown parameter '%s' (from %s)", name, layer.source)
+			}
+		}
+	}
+
+	result := make(map[string]interface{})
+	sources := make(map[string]string)
+	for _, name := range sortedKeys(schema) {
+		paramSchema := schema[name]
+
+		var value interface{}
+		var source string
+		for _, layer := range layers {
+			if v, ok := layer.values[name]; ok {
+				value, source = v, layer.source
+				break
+			}
+		}
+
+		switch {
+		case source != "":
+			converted, err := convertParam(value, paramSchema)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parameter '%s' (from %s): %v", name, source, err)
+			}
+			result[name], sources[name] = converted, source
+		case paramSchema.Default != nil:
+			// Send the advertised default so the server cannot pick a
+			// different one. Defaults the CLI cannot convert are sent as-is.
+			converted, err := convertParam(paramSchema.Default, paramSchema)
+			if err != nil {
+				converted = paramSchema.Default
+			}
+			result[name], sources[name] = converted, sourceDefault
+		case paramSchema.Required:
+			return nil, nil, fmt.Errorf("required parameter '%s' is missing", name)
+		}
+	}
+	return result, sources, nil
+}
+
+// unsetRequired returns the required parameters that have no value in any
+// layer, whether or not the schema has a default for them.
+func unsetRequired(layers []paramLayer, schema map[string]client.ParamSchema) []string {
+	var unset []string
+	for _, name := range sortedKeys(schema) {
+		if !schema[name].Required {
+			continue
+		}
+		found := false
+		for _, layer := range layers {
+			if _, ok := layer.values[name]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unset = append(unset, name)
+		}
+	}
+	return unset
+}
+
+// envLayer reads the parameters of the schema from the environment, using
+// prefix followed by the upper-cased parameter name.
+func envLayer(prefix string, schema map[string]client.ParamSchema) paramLayer {
+	layer := paramLayer{source: sourceEnv, values: map[string]interface{}{}}
+	for name := range schema {
+		if value, ok := os.LookupEnv(paramEnvName(prefix, name)); ok {
+			layer.values[name] = value
+		}
+	}
+	return layer
+}
+
+var nonEnvChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func paramEnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(nonEnvChars.ReplaceAllString(name, "_"))
+}
+
+// convertParam converts a value given on the command line, or decoded from
+// a params file, to the schema type and checks the schema's constraints.
+// The result is the value sent to the server.
+func convertParam(value interface{}, schema client.ParamSchema) (interface{}, error) {
+	converted, err := convertValue(value, schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConstraints(converted, schema); err != nil {
+		return nil, err
+	}
+	if d, ok := converted.(time.Duration); ok {
+		return d.String(), nil
+	}
+	return converted, nil
+}
+
+// convertValue converts value to the Go representation of the schema type.
+// Durations become time.Duration and sizes a uint64 number of bytes.
+func convertValue(value interface{}, schema client.ParamSchema) (interface{}, error) {
+	switch schema.Type {
+	case client.ParamString, client.ParamInt, client.ParamBool, client.ParamFloat:
+		return convertScalar(value, schema.Type)
+	case client.ParamDuration:
+		return convertDuration(value)
+	case client.ParamSize:
+		return convertSize(value)
+	case client.ParamList:
+		return convertList(value, schema.Items)
+	case client.ParamMap:
+		return convertMap(value, schema.Items)
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", schema.Type)
+	}
+}
+
+func convertScalar(value interface{}, targetType string) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		switch targetType {
+		case client.ParamString:
+			return s, nil
+		case client.ParamInt:
+			if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+				return n, nil
+			}
+		case client.ParamBool:
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b, nil
+			}
+		case client.ParamFloat:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return f, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a valid %s", s, targetType)
+	}
+
+	switch v := value.(type) {
+	case int:
+		switch targetType {
+		case client.ParamInt:
+			return v, nil
+		case client.ParamFloat:
+			return float64(v), nil
+		case client.ParamString:
+			return strconv.Itoa(v), nil
+		}
+	case float64:
+		switch targetType {
+		case client.ParamFloat:
+			return v, nil
+		case client.ParamInt:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case client.ParamString:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+	case bool:
+		switch targetType {
+		case client.ParamBool:
+			return v, nil
+		case client.ParamString:
+			return strconv.FormatBool(v), nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %s", targetType, describeValue(value))
+}
+
+func convertDuration(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a duration such as 30s or 5m, got %s", describeValue(value))
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid duration (use e.g. 30s, 5m or 1h30m)", s)
+	}
+	return d, nil
+}
+
+func convertSize(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		n, err := humanize.ParseBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid size (use e.g. 512MiB, 1G or 1.5 GB)", v)
+		}
+		return n, nil
+	case int:
+		if v >= 0 {
+			return uint64(v), nil
+		}
+	case float64:
+		if v >= 0 && v == math.Trunc(v) {
+			return uint64(v), nil
+		}
+	}
+	return nil, fmt.Errorf("expected a size such as 1G or 512MiB, got %s", describeValue(value))
+}
+
+// convertList accepts a list from a params file or, on the command line,
+// either a comma-separated string or a flow sequence such as [1, 2].
+func convertList(value interface{}, items *client.ParamSchema) (interface{}, error) {
+	var elems []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		elems = v
+	case string:
+		s := strings.TrimSpace(v)
+		switch {
+		case strings.HasPrefix(s, "["):
+			if err := yaml.Unmarshal([]byte(s), &elems); err != nil {
+				return nil, fmt.Errorf("%q is not a valid list (use e.g. [a, b] or a,b)", v)
+			}
+		case s != "":
+			for _, elem := range strings.Split(s, ",") {
+				elems = append(elems, strings.TrimSpace(elem))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected a list, got %s", describeValue(value))
+	}
+
+	result := make([]interface{}, len(elems))
+	for i, elem := range elems {
+		if items == nil {
+			result[i] = elem
+			continue
+		}
+		converted, err := convertParam(elem, *items)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %v", i, err)
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// convertMap accepts a map from a params file or, on the command line, a
+// flow mapping such as {a: 1, b: 2}.
+func convertMap(value interface{}, items *client.ParamSchema) (interface{}, error) {
+	var entries map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		entries = v
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") || yaml.Unmarshal([]byte(v), &entries) != nil {
+			return nil, fmt.Errorf("%q is not a valid map (use e.g. {key: value})", v)
+		}
+	default:
+		return nil, fmt.Errorf("expected a map, got %s", describeValue(value))
+	}
+
+	result := make(map[string]interface{}, len(entries))
+	for _, key := range sortedKeys(entries) {
+		if items == nil {
+			result[key] = entries[key]
+			continue
+		}
+		converted, err := convertParam(entries[key], *items)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", key, err)
+		}
+		result[key] = converted
+	}
+	return result, nil
+}
+
+// checkConstraints checks a converted value against the enum, pattern and
+// range of the schema.
+func checkConstraints(value interface{}, schema client.ParamSchema) error {
+	if len(schema.Enum) > 0 {
+		plain := client.ParamSchema{Type: schema.Type, Items: schema.Items}
+		allowed := make([]string, len(schema.Enum))
+		found := false
+		for i, option := range schema.Enum {
+			allowed[i] = formatPayloadValue(option)
+			if converted, err := convertValue(option, plain); err == nil && reflect.DeepEqual(converted, value) {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is not one of %s", quoteParamValue(value), strings.Join(allowed, ", "))
+		}
+	}
+
+	if s, ok := value.(string); ok && schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema pattern %q is invalid: %v", schema.Pattern, err)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%q does not match pattern %s", s, schema.Pattern)
+		}
+	}
+
+	n, ok := numericValue(value)
+	if !ok {
+		return nil
+	}
+	plain := client.ParamSchema{Type: schema.Type}
+	if schema.Min != nil {
+		min, err := convertValue(schema.Min, plain)
+		if err != nil {
+			return fmt.Errorf("schema minimum %v is invalid: %v", schema.Min, err)
+		}
+		if m, _ := numericValue(min); n < m {
+			return fmt.Errorf("%s is below the minimum of %s", quoteParamValue(value), formatPayloadValue(schema.Min))
+		}
+	}
+	if schema.Max != nil {
+		max, err := convertValue(schema.Max, plain)
+		if err != nil {
+			return fmt.Errorf("schema maximum %v is invalid: %v", schema.Max, err)
+		}
+		if m, _ := numericValue(max); n > m {
+			return fmt.Errorf("%s is above the maximum of %s", quoteParamValue(value), formatPayloadValue(schema.Max))
+		}
+	}
+	return nil
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case uint64:
+		return float64(v), true
+	case time.Duration:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// quoteParamValue formats a converted value for error messages. Sizes are
+// the only values converted to uint64.
+func quoteParamValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case time.Duration:
+		return v.String()
+	case uint64:
+		return humanize.Bytes(v)
+	}
+	return formatPayloadValue(value)
+}
+
+// describeValue names the kind of a value for type mismatch errors.
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "a map"
+	case nil:
+		return "nothing"
+	}
+	return quoteParamValue(value)
+}
+
+// formatParamType describes a parameter's type and constraints, e.g.
+// "string (memory|device)" or "list<int (1..5)>".
+func formatParamType(p client.ParamSchema) string {
+	typ := p.Type
+	if p.Items != nil && (typ == client.ParamList || typ == client.ParamMap) {
+		typ += "<" + formatParamType(*p.Items) + ">"
+	}
+
+	var constraints []string
+	if len(p.Enum) > 0 {
+		options := make([]string, len(p.Enum))
+		for i, option := range p.Enum {
+			options[i] = formatPayloadValue(option)
+		}
+		constraints = append(constraints, strings.Join(options, "|"))
+	}
+	if p.Min != nil || p.Max != nil {
+		constraints = append(constraints, formatPayloadValue(p.Min)+".."+formatPayloadValue(p.Max))
+	}
+	if p.Pattern != "" {
+		constraints = append(constraints, "/"+p.Pattern+"/")
+	}
+	if len(constraints) > 0 {
+		typ += " (" + strings.Join(constraints, ", ") + ")"
+	}
+	return typ
+}
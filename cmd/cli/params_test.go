@@ -0,0 +1,314 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+)
+
+var testSchema = map[string]client.ParamSchema{
+	"namespace": {Type: client.ParamString, Required: true},
+	"replicas":  {Type: client.ParamInt, Default: 3},
+	"force":     {Type: client.ParamBool},
+	"mode":      {Type: client.ParamString, Required: true, Default: "rolling"},
+}
+
+func layer(source string, values map[string]interface{}) paramLayer {
+	return paramLayer{source: source, values: values}
+}
+
+func TestValidateAndConvertParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		layers      []paramLayer
+		want        map[string]interface{}
+		wantSources map[string]string
+		wantErr     string
+	}{
+		{
+			name:        "defaults only",
+			layers:      []paramLayer{layer(sourceFlag, map[string]interface{}{"namespace": "ns"})},
+			want:        map[string]interface{}{"namespace": "ns", "replicas": 3, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFlag, "replicas": sourceDefault, "mode": sourceDefault},
+		},
+		{
+			name: "flag beats file, env and context",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{"replicas": "5"}),
+				layer(sourceFile, map[string]interface{}{"replicas": 4, "namespace": "file-ns"}),
+				layer(sourceEnv, map[string]interface{}{"replicas": "2", "namespace": "env-ns", "force": "true"}),
+				layer(sourceContext, map[string]interface{}{"replicas": 1, "force": false, "mode": "canary"}),
+			},
+			want:        map[string]interface{}{"namespace": "file-ns", "replicas": 5, "force": true, "mode": "canary"},
+			wantSources: map[string]string{"namespace": sourceFile, "replicas": sourceFlag, "force": sourceEnv, "mode": sourceContext},
+		},
+		{
+			name: "file beats env",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{}),
+				layer(sourceFile, map[string]interface{}{"namespace": "file-ns"}),
+				layer(sourceEnv, map[string]interface{}{"namespace": "env-ns"}),
+			},
+			want:        map[string]interface{}{"namespace": "file-ns", "replicas": 3, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFile, "replicas": sourceDefault, "mode": sourceDefault},
+		},
+		{
+			name: "env beats default",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{"namespace": "ns"}),
+				layer(sourceEnv, map[string]interface{}{"replicas": "7", "mode": "blue-green"}),
+			},
+			want:        map[string]interface{}{"namespace": "ns", "replicas": 7, "mode": "blue-green"},
+			wantSources: map[string]string{"namespace": sourceFlag, "replicas": sourceEnv, "mode": sourceEnv},
+		},
+		{
+			name:    "required missing",
+			layers:  []paramLayer{layer(sourceFlag, map[string]interface{}{"replicas": "2"})},
+			wantErr: "required parameter 'namespace' is missing",
+		},
+		{
+			name:    "required missing in every layer",
+			layers:  []paramLayer{layer(sourceFlag, nil), layer(sourceFile, nil), layer(sourceEnv, nil)},
+			wantErr: "required parameter 'namespace' is missing",
+		},
+		{
+			name:    "unknown flag",
+			layers:  []paramLayer{layer(sourceFlag, map[string]interface{}{"namespace": "ns", "size": "1"})},
+			wantErr: "unknown parameter 'size' (from flag)",
+		},
+		{
+			name: "unknown file key",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{"namespace": "ns"}),
+				layer(sourceFile, map[string]interface{}{"size": 1}),
+			},
+			wantErr: "unknown parameter 'size' (from file)",
+		},
+		{
+			name: "unknown context key",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{"namespace": "ns"}),
+				layer(sourceContext, map[string]interface{}{"size": 1}),
+			},
+			wantErr: "unknown parameter 'size' (from context)",
+		},
+		{
+			name: "conversion error names the source",
+			layers: []paramLayer{
+				layer(sourceFlag, map[string]interface{}{"namespace": "ns"}),
+				layer(sourceEnv, map[string]interface{}{"replicas": "many"}),
+			},
+			wantErr: `parameter 'replicas' (from env): "many" is not a valid int`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, sources, err := validateAndConvertParams(tt.layers, testSchema)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestParamLayers(t *testing.T) {
+	const envPrefix = "GOCLUSTER_TEST_PARAM_"
+
+	tests := []struct {
+		name        string
+		flags       []string
+		file        string
+		env         map[string]string
+		want        map[string]interface{}
+		wantSources map[string]string
+		wantErr     string
+	}{
+		{
+			name:        "flag",
+			flags:       []string{"namespace=flag-ns"},
+			want:        map[string]interface{}{"namespace": "flag-ns", "replicas": 3, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFlag, "replicas": sourceDefault, "mode": sourceDefault},
+		},
+		{
+			name:        "file",
+			file:        "namespace: file-ns\nreplicas: 4\n",
+			want:        map[string]interface{}{"namespace": "file-ns", "replicas": 4, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFile, "replicas": sourceFile, "mode": sourceDefault},
+		},
+		{
+			name:        "env",
+			env:         map[string]string{"NAMESPACE": "env-ns", "FORCE": "true"},
+			want:        map[string]interface{}{"namespace": "env-ns", "replicas": 3, "force": true, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceEnv, "replicas": sourceDefault, "force": sourceEnv, "mode": sourceDefault},
+		},
+		{
+			name:        "flag over file over env",
+			flags:       []string{"replicas=6"},
+			file:        "namespace: file-ns\nreplicas: 4\n",
+			env:         map[string]string{"NAMESPACE": "env-ns", "REPLICAS": "2", "FORCE": "true"},
+			want:        map[string]interface{}{"namespace": "file-ns", "replicas": 6, "force": true, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFile, "replicas": sourceFlag, "force": sourceEnv, "mode": sourceDefault},
+		},
+		{
+			name:        "env outside the schema is ignored",
+			flags:       []string{"namespace=ns"},
+			env:         map[string]string{"SIZE": "1"},
+			want:        map[string]interface{}{"namespace": "ns", "replicas": 3, "mode": "rolling"},
+			wantSources: map[string]string{"namespace": sourceFlag, "replicas": sourceDefault, "mode": sourceDefault},
+		},
+		{
+			name:    "unknown flag",
+			flags:   []string{"namespace=ns", "size=1"},
+			wantErr: "unknown parameter 'size' (from flag)",
+		},
+		{
+			name:    "unknown file key",
+			flags:   []string{"namespace=ns"},
+			file:    "size: 1\n",
+			wantErr: "unknown parameter 'size' (from file)",
+		},
+		{
+			name:    "required missing",
+			file:    "replicas: 4\n",
+			wantErr: "required parameter 'namespace' is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().StringToStringP("params", "p", nil, "")
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set("params", flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file := ""
+			if tt.file != "" {
+				file = filepath.Join(t.TempDir(), "params.yaml")
+				if err := os.WriteFile(file, []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for name, value := range tt.env {
+				t.Setenv(envPrefix+name, value)
+			}
+
+			layers, err := paramLayers(cmd, "test", "params", file, envPrefix, testSchema)
+			if err != nil {
+				t.Fatalf("paramLayers: %v", err)
+			}
+			got, sources, err := validateAndConvertParams(layers, testSchema)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestContextLayer(t *testing.T) {
+	saved := config
+	t.Cleanup(func() { config = saved })
+	config = Config{
+		CLI: CLIConfig{CurrentContext: "prod"},
+		Contexts: map[string]ContextConfig{
+			"prod": {
+				Cluster: "prod",
+				Operators: map[string]OperatorDefaults{
+					// Viper lowercases map keys.
+					"test": {Params: map[string]interface{}{"namespace": "ctx-ns", "replicationfactor": 3}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		schema map[string]client.ParamSchema
+		want   map[string]interface{}
+	}{
+		{
+			name:   "keys outside the operation are dropped",
+			schema: map[string]client.ParamSchema{"force": {Type: client.ParamBool}},
+			want:   map[string]interface{}{},
+		},
+		{
+			name:   "keys match case-insensitively",
+			schema: map[string]client.ParamSchema{"namespace": {Type: client.ParamString}, "replicationFactor": {Type: client.ParamInt}},
+			want:   map[string]interface{}{"namespace": "ctx-ns", "replicationFactor": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contextLayer("test", "params", tt.schema)
+			if got.source != sourceContext {
+				t.Errorf("source = %q, want %q", got.source, sourceContext)
+			}
+			if !reflect.DeepEqual(got.values, tt.want) {
+				t.Errorf("values = %v, want %v", got.values, tt.want)
+			}
+			if _, _, err := validateAndConvertParams([]paramLayer{got}, tt.schema); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnsetRequired(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []paramLayer
+		want   []string
+	}{
+		{name: "nothing set", want: []string{"mode", "namespace"}},
+		{
+			name:   "set in a lower layer",
+			layers: []paramLayer{layer(sourceFlag, nil), layer(sourceContext, map[string]interface{}{"namespace": "ns"})},
+			want:   []string{"mode"},
+		},
+		{
+			name:   "all set",
+			layers: []paramLayer{layer(sourceEnv, map[string]interface{}{"namespace": "ns", "mode": "canary"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unsetRequired(tt.layers, testSchema); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unsetRequired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
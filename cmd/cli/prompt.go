@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+)
+
+// stdinReader is shared by all prompts so buffered input is not lost
+// between them.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal. It returns false without
+// asking when stdin is not a terminal, so scripts must pass --yes, and when
+// ctx is cancelled while waiting for the answer.
+func confirm(ctx context.Context, question string) bool {
+	if !isTerminal(os.Stdin) {
+		fmt.Printf("%s\nRefusing to continue without a terminal; pass --yes to confirm.\n", question)
+		return false
+	}
+
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := readLine(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Println()
+		}
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// readLine reads a line from stdin. It gives up when ctx is cancelled, as
+// the interrupt signal is caught and would not stop a blocked read.
+func readLine(ctx context.Context) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		line, err := stdinReader.ReadString('\n')
+		ch <- result{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
+	case r := <-ch:
+		return r.line, r.err
+	}
+}
+
+// promptParams asks for each named parameter on the terminal and returns
+// the answers as a layer taking precedence over all others.
+func promptParams(ctx context.Context, section string, names []string, schema map[string]client.ParamSchema) (paramLayer, error) {
+	layer := paramLayer{source: sourcePrompt, values: map[string]interface{}{}}
+	for _, name := range names {
+		value, err := promptParam(ctx, section, name, schema[name])
+		if err != nil {
+			return layer, err
+		}
+		if value != nil {
+			layer.values[name] = value
+		}
+	}
+	return layer, nil
+}
+
+// promptParam asks for a single value until it passes validation. Enum
+// values are offered as a numbered list. An empty answer accepts the
+// schema default, for which nil is returned so the value keeps its source.
+func promptParam(ctx context.Context, section, name string, param client.ParamSchema) (interface{}, error) {
+	fmt.Printf("\n%s %s (%s)", section, name, formatParamType(param))
+	if param.Description != "" {
+		fmt.Printf(": %s", param.Description)
+	}
+	fmt.Println()
+	for i, option := range param.Enum {
+		fmt.Printf("  %d) %s\n", i+1, formatPayloadValue(option))
+	}
+
+	for {
+		if param.Default != nil {
+			fmt.Printf("> [%s] ", formatPayloadValue(param.Default))
+		} else {
+			fmt.Print("> ")
+		}
+		answer, err := readLine(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("no value given for %s", name)
+		}
+
+		var value interface{} = strings.TrimSpace(answer)
+		switch {
+		case value == "" && param.Default != nil:
+			return nil, nil
+		case value == "":
+			fmt.Println("  a value is required")
+			continue
+		}
+
+		_, err = convertParam(value, param)
+		if err != nil && len(param.Enum) > 0 {
+			// Not a valid value itself; try it as a choice from the list.
+			if i, convErr := strconv.Atoi(strings.TrimSpace(answer)); convErr == nil && i >= 1 && i <= len(param.Enum) {
+				value, err = param.Enum[i-1], nil
+			}
+		}
+		if err != nil {
+			fmt.Printf("  %v\n", err)
+			continue
+		}
+		return value, nil
+	}
+}
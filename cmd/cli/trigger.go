@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Prajwalprakash3722/gocluster-cli/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func triggerOperator(cmd *cobra.Command, args []string) {
+	operatorName := args[0]
+	operationName := args[1]
+
+	cluster, err := getSelectedCluster()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	c := newClient(cluster)
+
+	schema, err := fetchOperatorSchema(cmd.Context(), c, operatorName)
+	if err != nil {
+		fmt.Println("Error fetching operator schema:", err)
+		return
+	}
+
+	opSchema, exists := schema.Operations[operationName]
+	if !exists {
+		fmt.Printf("Operation '%s' not found for operator '%s'\n", operationName, operatorName)
+		fmt.Println("\nAvailable operations:")
+		for op := range schema.Operations {
+			fmt.Printf("- %s\n", op)
+		}
+		return
+	}
+
+	if triggerParamsFile == "-" && triggerConfigFile == "-" {
+		fmt.Println("Error: only one of --params-file and --config-file can read from stdin")
+		return
+	}
+	params, err := paramLayers(cmd, operatorName, "params", triggerParamsFile, paramEnvPrefix, opSchema.Parameters)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	config, err := paramLayers(cmd, operatorName, "config", triggerConfigFile, configEnvPrefix, opSchema.Config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// On a terminal, ask for missing required values instead of failing.
+	// Once asking, required values with a default are offered too, with
+	// the default taken on Enter. Scripts keep getting the validation
+	// error below.
+	prompted := false
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		sections := []struct {
+			name   string
+			layers *[]paramLayer
+			schema map[string]client.ParamSchema
+			unset  []string
+		}{
+			{name: "param", layers: &params, schema: opSchema.Parameters},
+			{name: "config", layers: &config, schema: opSchema.Config},
+		}
+		missing := false
+		for i := range sections {
+			section := &sections[i]
+			section.unset = unsetRequired(*section.layers, section.schema)
+			for _, name := range section.unset {
+				missing = missing || section.schema[name].Default == nil
+			}
+		}
+		if missing {
+			fmt.Println("Some required values are missing; enter them below or press Ctrl-C to abort.")
+			for _, section := range sections {
+				if len(section.unset) == 0 {
+					continue
+				}
+				layer, err := promptParams(cmd.Context(), section.name, section.unset, section.schema)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				*section.layers = append([]paramLayer{layer}, *section.layers...)
+			}
+			prompted = true
+		}
+	}
+
+	validatedParams, paramSources, err := validateAndConvertParams(params, opSchema.Parameters)
+	if err != nil {
+		fmt.Printf("Parameter validation error: %v\n", err)
+		fmt.Println("\nRequired parameters:")
+		for name, param := range opSchema.Parameters {
+			if param.Required {
+				fmt.Printf("- %s (%s): %s\n", name, formatParamType(param), param.Description)
+			}
+		}
+		return
+	}
+
+	validatedConfig, configSources, err := validateAndConvertParams(config, opSchema.Config)
+	if err != nil {
+		fmt.Printf("Config validation error: %v\n", err)
+		return
+	}
+
+	targets, err := resolveTargetNodes(cluster)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	payload := client.OperatorPayload{
+		Operation:   operationName,
+		Params:      validatedParams,
+		Config:      validatedConfig,
+		Parallel:    parallel,
+		TargetNodes: targetNodes,
+	}
+
+	sources := map[string]map[string]string{"params": paramSources, "config": configSources}
+	switch {
+	case triggerShowResolved:
+		printListing(resolvedListing(payload, sources, opSchema))
+		return
+	case triggerDryRun:
+		printDryRun(cmd.Context(), c, operatorName, payload, sources, opSchema, targets)
+		return
+	case prompted:
+		fmt.Println()
+		printListing(resolvedListing(payload, sources, opSchema))
+		if !confirm(cmd.Context(), fmt.Sprintf("Trigger %s %s on %s?", operatorName, operationName, strings.Join(targets, ", "))) {
+			fmt.Println("Trigger cancelled")
+			return
+		}
+	}
+
+	result, err := c.TriggerOperation(cmd.Context(), operatorName, payload)
+	if err != nil {
+		fmt.Printf("Failed to trigger operation: %v\n", err)
+		return
+	}
+
+	fmt.Println("Operation triggered successfully")
+	if result.JobID == "" {
+		return
+	}
+	fmt.Printf("Job ID: %v\n", result.JobID)
+	if triggerWait {
+		os.Exit(waitForJob(cmd.Context(), c, result.JobID))
+	}
+	fmt.Println("Use 'gocluster operator status <job_id>' to check the status")
+}
+
+// paramLayers collects the values of one section of the payload in order
+// of precedence: the key=value pairs of the named flag, the file given for
+// it, the environment and the operator defaults of the current context.
+// Schema defaults are applied last by validateAndConvertParams.
+func paramLayers(cmd *cobra.Command, operatorName, flag, file, envPrefix string, schema map[string]client.ParamSchema) ([]paramLayer, error) {
+	flagValues, _ := cmd.Flags().GetStringToString(flag)
+	layer := paramLayer{source: sourceFlag, values: map[string]interface{}{}}
+	for key, value := range flagValues {
+		layer.values[key] = value
+	}
+	layers := []paramLayer{layer}
+
+	if file != "" {
+		values, err := readValuesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, paramLayer{source: sourceFile, values: values})
+	}
+	return append(layers, envLayer(envPrefix, schema), contextLayer(operatorName, flag, schema)), nil
+}
+
+// readValuesFile decodes a YAML or JSON mapping of values from path, or
+// from stdin if path is "-".
+func readValuesFile(path string) (map[string]interface{}, error) {
+	var r io.Reader = stdinReader
+	name := "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r, name = f, path
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.NewDecoder(r).Decode(&values); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return values, nil
+}
+
+// resolveTargetNodes returns the nodes an operation will run on: the
+// --nodes selection if given, otherwise every node of the cluster.
+func resolveTargetNodes(cluster *client.ClusterConfig) ([]string, error) {
+	if len(targetNodes) == 0 {
+		return sortedKeys(cluster.Nodes), nil
+	}
+	for _, node := range targetNodes {
+		if _, ok := cluster.Nodes[node]; !ok {
+			return nil, fmt.Errorf("unknown node '%s' in --nodes (cluster '%s' has: %s)",
+				node, cluster.Name, strings.Join(sortedKeys(cluster.Nodes), ", "))
+		}
+	}
+	return targetNodes, nil
+}
+
+// dryRun is what 'operator trigger --dry-run' reports for -o json/yaml.
+type dryRun struct {
+	Operator    string                 `json:"operator"`
+	Payload     client.OperatorPayload `json:"payload"`
+	TargetNodes []string               `json:"resolved_target_nodes"`
+	Plan        *client.OperationPlan  `json:"plan,omitempty"`
+	PlanError   string                 `json:"plan_error,omitempty"`
+	// Sources maps "params" and "config" to the source of each value.
+	Sources map[string]map[string]string `json:"sources"`
+}
+
+// printDryRun shows the validated payload and, if the server can plan
+// operations, what it would change. Nothing is executed.
+func printDryRun(ctx context.Context, c *client.Client, operatorName string, payload client.OperatorPayload, sources map[string]map[string]string, opSchema client.OperationSchema, targets []string) {
+	result := dryRun{
+		Operator:    operatorName,
+		Payload:     payload,
+		TargetNodes: targets,
+		Sources:     sources,
+	}
+	plan, err := c.PlanOperation(ctx, operatorName, payload)
+	switch {
+	case errors.Is(err, client.ErrPlanUnsupported):
+	case err != nil:
+		result.PlanError = err.Error()
+	default:
+		result.Plan = plan
+	}
+
+	l := resolvedListing(payload, sources, opSchema)
+	l.data = result
+
+	if !isTableOutput() {
+		printListing(l)
+		return
+	}
+
+	fmt.Println("Dry run: nothing will be executed.")
+	fmt.Println()
+	fmt.Printf("Operator:     %s\n", operatorName)
+	fmt.Printf("Operation:    %s\n", payload.Operation)
+	fmt.Printf("Parallel:     %t\n", payload.Parallel)
+	if len(payload.TargetNodes) == 0 {
+		fmt.Printf("Target nodes: all (%s)\n", strings.Join(targets, ", "))
+	} else {
+		fmt.Printf("Target nodes: %s\n", strings.Join(targets, ", "))
+	}
+	fmt.Println()
+	printListing(l)
+
+	raw, _ := json.MarshalIndent(payload, "", "  ")
+	fmt.Printf("\nPayload:\n%s\n\n", raw)
+
+	switch {
+	case result.Plan != nil:
+		printPlan(result.Plan)
+	case result.PlanError != "":
+		fmt.Printf("Plan unavailable: %s\n", result.PlanError)
+	default:
+		fmt.Println("The server does not support plans; the payload was validated locally only.")
+	}
+}
+
+// resolvedParam is a resolved value and where it came from.
+type resolvedParam struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// resolvedListing lists the params and config of payload with the source
+// each value was resolved from.
+func resolvedListing(payload client.OperatorPayload, sources map[string]map[string]string, opSchema client.OperationSchema) *listing {
+	data := map[string]map[string]resolvedParam{}
+	l := &listing{
+		data:   data,
+		header: []string{"Section", "Name", "Value", "Type", "Source"},
+	}
+	for _, section := range []struct {
+		name   string
+		values map[string]interface{}
+		schema map[string]client.ParamSchema
+	}{
+		{"params", payload.Params, opSchema.Parameters},
+		{"config", payload.Config, opSchema.Config},
+	} {
+		data[section.name] = map[string]resolvedParam{}
+		for _, name := range sortedKeys(section.values) {
+			value, source := section.values[name], sources[section.name][name]
+			data[section.name][name] = resolvedParam{Value: value, Source: source}
+			l.rows = append(l.rows, []string{section.name, name, formatPayloadValue(value), formatParamType(section.schema[name]), source})
+			l.names = append(l.names, section.name+"."+name)
+		}
+	}
+	return l
+}
+
+func printPlan(plan *client.OperationPlan) {
+	fmt.Println("Plan:")
+	if plan.Summary != "" {
+		fmt.Println(plan.Summary)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+
+	l := &listing{
+		header: []string{"Node", "Action", "Path", "Before", "After"},
+	}
+	for _, change := range plan.Changes {
+		l.rows = append(l.rows, []string{
+			change.Node,
+			change.Action,
+			change.Path,
+			formatPayloadValue(change.Before),
+			formatPayloadValue(change.After),
+		})
+	}
+	printListing(l)
+}
+
+// formatPayloadValue renders scalars as-is and lists or objects as JSON.
+func formatPayloadValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string, bool, int, int64, float64:
+		return fmt.Sprint(v)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
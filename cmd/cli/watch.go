@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxWatchEvents is how many recent events are kept below the table.
+const maxWatchEvents = 10
+
+// snapshot is one poll of a watched command.
+type snapshot struct {
+	listing *listing
+	// states maps a key such as a node ID or "leader" to the value
+	// transitions are detected on.
+	states map[string]string
+	// rowKeys holds the state key of every listing row.
+	rowKeys []string
+}
+
+// watch polls at --interval until ctx is cancelled. On a terminal the table
+// is redrawn in place with changed rows highlighted and the latest events
+// below it; otherwise the first snapshot is printed followed by one
+// timestamped line per transition.
+func watch(ctx context.Context, title string, poll func(context.Context) (*snapshot, error)) {
+	redraw := isTerminal(os.Stdout) && isTableOutput()
+
+	var prev *snapshot
+	var events []string
+	for {
+		snap, err := poll(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+		var changed []string
+		switch {
+		case err != nil:
+			changed = []string{fmt.Sprintf("poll failed: %v", err)}
+			snap = prev
+		case prev != nil:
+			changed = diffStates(prev.states, snap.states)
+			highlightChanges(prev, snap)
+		}
+
+		stamped := make([]string, len(changed))
+		for i, event := range changed {
+			stamped[i] = fmt.Sprintf("%s  %s", now.Format("15:04:05"), event)
+		}
+		events = append(events, stamped...)
+		if len(events) > maxWatchEvents {
+			events = events[len(events)-maxWatchEvents:]
+		}
+
+		if redraw {
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Every %s: %s    %s\n\n", watchInterval, title, now.Format(time.RFC1123))
+			if snap != nil {
+				printListing(snap.listing)
+			}
+			if len(events) > 0 {
+				fmt.Println("\nEvents:")
+				for _, event := range events {
+					fmt.Println(event)
+				}
+			}
+		} else {
+			if prev == nil && err == nil {
+				printListing(snap.listing)
+			}
+			for _, event := range stamped {
+				fmt.Println(event)
+			}
+		}
+		if err == nil {
+			prev = snap
+		}
+
+		timer := time.NewTimer(watchInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// diffStates describes every key that appeared, disappeared or changed.
+func diffStates(prev, next map[string]string) []string {
+	var events []string
+	for _, key := range sortedKeys(next) {
+		old, existed := prev[key]
+		switch {
+		case !existed:
+			events = append(events, fmt.Sprintf("%s: appeared (%s)", key, next[key]))
+		case old != next[key]:
+			events = append(events, fmt.Sprintf("%s: %s -> %s", key, old, next[key]))
+		}
+	}
+	for _, key := range sortedKeys(prev) {
+		if _, exists := next[key]; !exists {
+			events = append(events, fmt.Sprintf("%s: disappeared (was %s)", key, prev[key]))
+		}
+	}
+	return events
+}
+
+// highlightChanges marks the rows of next whose state differs from prev.
+func highlightChanges(prev, next *snapshot) {
+	next.listing.highlight = make([]bool, len(next.rowKeys))
+	for i, key := range next.rowKeys {
+		old, existed := prev.states[key]
+		next.listing.highlight[i] = !existed || old != next.states[key]
+	}
+}